@@ -0,0 +1,102 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import "fmt"
+
+// UnclosedError is returned in strict mode when input ends in a string literal, a bytes literal,
+// a quoted identifier, or a multiline comment.
+type UnclosedError struct {
+	// Offset is the byte offset of the beginning of the unclosed token.
+	Offset int
+	// Delimiter is the expected closing delimiter.
+	Delimiter string
+	// Kind is the kind of the unclosed token. If it is WaitingNone, it is inferred from Delimiter.
+	Kind WaitingKind
+	// CommentMarker is the first comment marker like `--` in the unclosed string literal, or "" if none.
+	// It is not a comment but a part of the string, which often means a stray quote before it.
+	CommentMarker string
+}
+
+func (e *UnclosedError) Error() string {
+	kind := e.Kind
+	if kind == WaitingNone {
+		kind = waitingKind(e.Delimiter)
+	}
+	msg := fmt.Sprintf("unclosed %v at offset %d: expecting %q", kind, e.Offset, e.Delimiter)
+	if e.CommentMarker != "" {
+		msg += fmt.Sprintf(" (the string contains comment marker %q, possibly after a stray quote)", e.CommentMarker)
+	}
+	return msg
+}
+
+// TooManyStatementsError is returned when input has more statements than the limit configured by WithMaxStatements.
+type TooManyStatementsError struct {
+	// Max is the limit of the number of statements.
+	Max int
+	// Count is the number of statements seen, including the one exceeding Max.
+	Count int
+}
+
+func (e *TooManyStatementsError) Error() string {
+	return fmt.Sprintf("too many statements: %d statements exceed the limit of %d", e.Count, e.Max)
+}
+
+// StatementTooLongError is returned when a statement exceeds the limit configured by WithMaxStatementLength.
+type StatementTooLongError struct {
+	// Offset is the byte offset of the beginning of the statement.
+	Offset int
+	// Max is the limit of the length of a statement in runes.
+	Max int
+}
+
+func (e *StatementTooLongError) Error() string {
+	return fmt.Sprintf("statement at offset %d exceeds the limit of %d characters", e.Offset, e.Max)
+}
+
+// InvalidEscapeError is returned when a string or bytes literal has an invalid escape sequence,
+// if escape sequences are validated by WithStrictEscapes.
+type InvalidEscapeError struct {
+	// Offset is the byte offset of the backslash of the invalid escape sequence.
+	Offset int
+	// Sequence is the backslash and the following character.
+	Sequence string
+}
+
+func (e *InvalidEscapeError) Error() string {
+	return fmt.Sprintf("invalid escape sequence %q at offset %d", e.Sequence, e.Offset)
+}
+
+// MissingTerminatorError is returned by Validate when the last statement isn't terminated.
+type MissingTerminatorError struct {
+	// Offset is the byte offset where the terminator is expected, which is the end of the last statement.
+	Offset int
+}
+
+func (e *MissingTerminatorError) Error() string {
+	return fmt.Sprintf("missing terminator at offset %d", e.Offset)
+}
+
+// InvalidUTF8Error is returned when input has an invalid UTF-8 byte, if input is validated by WithValidateUTF8.
+type InvalidUTF8Error struct {
+	// Offset is the byte offset of the invalid byte.
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("invalid UTF-8 byte at offset %d", e.Offset)
+}
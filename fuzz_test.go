@@ -0,0 +1,57 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzSeparateInput(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"SELECT 1; SELECT 2\\G",
+		"SELECT 'a;', \"b;\", `c;`, r'\\', b\"\\\"\", rb'''x''', br\"\"\"y",
+		"SELECT 1 -- comment;\n# comment\n/* comment; */;",
+		"SELECT '''a;''''';\"\"\"",
+		"rb",
+		"Rb;",
+		"r",
+		"/*",
+		"\uFEFFSELECT 1",
+		"SELECT '\xff;'",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		SeparateInput(input, `\G`)
+		SeparateInputStrict(input, `\G`)
+		Tokenize(input, WithTerminators(`\G`), WithDollarQuoting(true), WithNestedComments(true))
+		NewSeparator(input,
+			WithTerminators(`\G`, "go"), WithCaseInsensitiveTerminators(true), WithEscapeStringPrefix(true),
+			WithStrictEscapes(true), WithLeadingComments(true), WithTrailingComments(true), WithTypedLiterals(true),
+			WithCollectPlaceholders(true), WithLineContinuation(true), WithHintAwareness(true),
+			WithBracketAwareTerminators(true), WithDelimiterCommand(true), WithLineCommentPrefixes("//"),
+		).ReadAll()
+
+		// joining preserved statements and terminators reproduces input
+		stmts, _ := NewSeparator(input, WithTerminators(`\G`), WithPreserveComments(true), WithTrimSpace(false)).separate()
+		if got, want := Join(stmts), strings.TrimPrefix(input, bom); got != want {
+			t.Errorf("Join() = %q, but want = %q", got, want)
+		}
+	})
+}
@@ -4,5 +4,5 @@ go 1.18
 
 require (
 	github.com/google/go-cmp v0.5.9
-	golang.org/x/exp v0.0.0-20230310171629-522b1b587ee0
+	golang.org/x/text v0.14.0
 )
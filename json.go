@@ -0,0 +1,53 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalStatements returns the JSON encoding of stmts as an array of objects,
+// whose keys are the JSON tags of InputStatement like "statement", "terminator", and "start".
+// Optional fields like "leading_comments" are omitted if they are empty.
+// It returns an empty array `[]`, not `null`, if stmts is empty.
+func MarshalStatements(stmts []InputStatement) ([]byte, error) {
+	if stmts == nil {
+		stmts = []InputStatement{}
+	}
+	return json.Marshal(stmts)
+}
+
+// MarshalText implements encoding.TextMarshaler. The text is the name returned by String, like "string literal".
+// It returns an error for an unknown kind.
+func (k WaitingKind) MarshalText() ([]byte, error) {
+	if k < WaitingNone || k > WaitingComment {
+		return nil, fmt.Errorf("unknown WaitingKind: %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, which accepts the name returned by String.
+func (k *WaitingKind) UnmarshalText(text []byte) error {
+	for kind := WaitingNone; kind <= WaitingComment; kind++ {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown WaitingKind: %q", text)
+}
@@ -0,0 +1,121 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshalStatements(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		stmts []InputStatement
+		want  string
+	}{
+		{
+			desc:  "statements",
+			stmts: SeparateInput("SELECT 1;\nSELECT 'a'"),
+			want: `[` +
+				`{"statement":"SELECT 1","terminator":";","start":0,"end":8,"line":1,"column":1,"terminator_offset":8,"terminated":true,"index":0},` +
+				`{"statement":"SELECT 'a'","terminator":"","start":10,"end":20,"line":2,"column":1,"terminator_offset":-1,"terminated":false,"index":1}` +
+				`]`,
+		},
+		{
+			desc: "optional fields",
+			stmts: []InputStatement{{
+				Statement:        "SELECT @a",
+				Terminator:       ";",
+				LeadingComments:  []string{"-- a"},
+				TrailingComments: []string{"/* b */"},
+				Placeholders:     []Placeholder{{Text: "@a", Offset: 7}},
+				Source:           "-- a\nSELECT @a /* b */;",
+				Header:           "/* header */",
+			}},
+			want: `[{"statement":"SELECT @a","terminator":";","start":0,"end":0,"line":0,"column":0,"terminator_offset":0,"terminated":false,` +
+				`"leading_comments":["-- a"],"trailing_comments":["/* b */"],"placeholders":[{"text":"@a","offset":7}],"index":0,` +
+				`"source":"-- a\nSELECT @a /* b */;","header":"/* header */"}]`,
+		},
+		{
+			desc:  "no statements",
+			stmts: SeparateInput(""),
+			want:  `[]`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			b, err := MarshalStatements(tt.stmts)
+			if err != nil {
+				t.Fatalf("MarshalStatements() returns error: %v", err)
+			}
+			if got := string(b); got != tt.want {
+				t.Errorf("MarshalStatements() = %s, but want = %s", got, tt.want)
+			}
+
+			var stmts []InputStatement
+			if err := json.Unmarshal(b, &stmts); err != nil {
+				t.Fatalf("json.Unmarshal() returns error: %v", err)
+			}
+			if len(tt.stmts) == 0 {
+				return
+			}
+			if diff := cmp.Diff(tt.stmts, stmts); diff != "" {
+				t.Errorf("difference in unmarshaled statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStatus_JSON(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{"SELECT 1", `{"waiting_string":"","waiting_kind":"none"}`},
+		{"SELECT '''a", `{"waiting_string":"'''","waiting_kind":"string literal"}`},
+		{"SELECT `a", "{\"waiting_string\":\"`\",\"waiting_kind\":\"quoted identifier\"}"},
+		{"SELECT 1 /* a", `{"waiting_string":"*/","waiting_kind":"comment"}`},
+	} {
+		_, status := SeparateInputWithStatus(tt.input)
+		b, err := json.Marshal(status)
+		if err != nil {
+			t.Fatalf("json.Marshal(%#v) returns error: %v", status, err)
+		}
+		if got := string(b); got != tt.want {
+			t.Errorf("json.Marshal(%#v) = %s, but want = %s", status, got, tt.want)
+		}
+
+		var got Status
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s) returns error: %v", b, err)
+		}
+		if diff := cmp.Diff(status, got); diff != "" {
+			t.Errorf("difference in unmarshaled status: (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestWaitingKind_JSONError(t *testing.T) {
+	if b, err := json.Marshal(Status{WaitingKind: WaitingKind(100)}); err == nil {
+		t.Errorf("json.Marshal() of unknown WaitingKind = %s, but want error", b)
+	}
+	var status Status
+	if err := json.Unmarshal([]byte(`{"waiting_kind":"unknown"}`), &status); err == nil {
+		t.Errorf("json.Unmarshal() of unknown WaitingKind = %#v, but want error", status)
+	}
+}
@@ -0,0 +1,144 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// StatementKind is a kind of a statement classified by its leading keyword.
+type StatementKind int
+
+const (
+	// KindUnknown is a statement which doesn't start with any known keyword.
+	KindUnknown StatementKind = iota
+	// KindQuery is a query statement, which starts with SELECT or WITH.
+	KindQuery
+	// KindDML is a data manipulation language statement, which starts with INSERT, UPDATE, or DELETE.
+	KindDML
+	// KindDDL is a data definition language statement, which starts with CREATE, ALTER, or DROP.
+	KindDDL
+	// KindDCL is a data control language statement, which starts with GRANT or REVOKE.
+	KindDCL
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case KindUnknown:
+		return "Unknown"
+	case KindQuery:
+		return "Query"
+	case KindDML:
+		return "DML"
+	case KindDDL:
+		return "DDL"
+	case KindDCL:
+		return "DCL"
+	default:
+		return fmt.Sprintf("StatementKind(%d)", int(k))
+	}
+}
+
+var statementKinds = map[string]StatementKind{
+	"SELECT": KindQuery,
+	"WITH":   KindQuery,
+	"INSERT": KindDML,
+	"UPDATE": KindDML,
+	"DELETE": KindDML,
+	"CREATE": KindDDL,
+	"ALTER":  KindDDL,
+	"DROP":   KindDDL,
+	"GRANT":  KindDCL,
+	"REVOKE": KindDCL,
+}
+
+// Kind classifies the statement by its leading keyword, case-insensitively.
+// Leading whitespaces and comments are skipped.
+func (stmt InputStatement) Kind() StatementKind {
+	return statementKinds[leadingKeyword(stmt.Statement)]
+}
+
+// FilterKind separates input for each statement as same as SeparateInput, and returns only statements of kind.
+// It returns nil if no statement is of kind.
+func FilterKind(input string, kind StatementKind, customTerminators ...string) []InputStatement {
+	var result []InputStatement
+	for _, stmt := range SeparateInput(input, customTerminators...) {
+		if stmt.Kind() == kind {
+			result = append(result, stmt)
+		}
+	}
+	return result
+}
+
+// GroupTransactions groups stmts by explicit transactions, which start with BEGIN or START TRANSACTION,
+// and end with COMMIT or ROLLBACK. Keywords are matched case-insensitively after whitespaces and comments.
+// Each group of a transaction contains statements from its start to its end, both inclusive,
+// and each statement outside of transactions is a group by itself.
+// An unclosed transaction is grouped to the end of stmts.
+// It returns nil if stmts is empty.
+func GroupTransactions(stmts []InputStatement) [][]InputStatement {
+	var groups [][]InputStatement
+	inTransaction := false
+	for _, stmt := range stmts {
+		keyword, rest := nextKeyword(stmt.Statement)
+		switch {
+		case inTransaction:
+			last := len(groups) - 1
+			groups[last] = append(groups[last], stmt)
+			inTransaction = keyword != "COMMIT" && keyword != "ROLLBACK"
+		case keyword == "BEGIN":
+			groups = append(groups, []InputStatement{stmt})
+			inTransaction = true
+		case keyword == "START":
+			groups = append(groups, []InputStatement{stmt})
+			second, _ := nextKeyword(rest)
+			inTransaction = second == "TRANSACTION"
+		default:
+			groups = append(groups, []InputStatement{stmt})
+		}
+	}
+	return groups
+}
+
+// leadingKeyword returns the uppercased first word of s after whitespaces and comments.
+func leadingKeyword(s string) string {
+	word, _ := nextKeyword(s)
+	return word
+}
+
+// nextKeyword returns the uppercased first word of s after whitespaces and comments, and the rest of s after the word.
+func nextKeyword(s string) (word, rest string) {
+	sep := newSeparator(s, false, nil)
+	for {
+		sep.str = strings.TrimLeftFunc(sep.str, unicode.IsSpace)
+		n := len(sep.str)
+		sep.skipComments()
+		if len(sep.str) == n {
+			break
+		}
+	}
+
+	word = sep.str
+	if i := strings.IndexFunc(word, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}); i >= 0 {
+		word = word[:i]
+	}
+	return strings.ToUpper(word), sep.str[len(word):]
+}
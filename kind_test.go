@@ -0,0 +1,150 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInputStatement_Kind(t *testing.T) {
+	for _, tt := range []struct {
+		statement string
+		want      StatementKind
+	}{
+		{"SELECT 1", KindQuery},
+		{"select 1", KindQuery},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", KindQuery},
+		{"INSERT INTO t (a) VALUES (1)", KindDML},
+		{"Update t SET a = 1 WHERE TRUE", KindDML},
+		{"DELETE FROM t WHERE TRUE", KindDML},
+		{"CREATE TABLE t (a INT64) PRIMARY KEY (a)", KindDDL},
+		{"ALTER TABLE t ADD COLUMN b INT64", KindDDL},
+		{"DROP TABLE t", KindDDL},
+		{"GRANT SELECT ON TABLE t TO ROLE r", KindDCL},
+		{"REVOKE SELECT ON TABLE t FROM ROLE r", KindDCL},
+		{"-- comment\n/* comment */ # comment\n  SELECT 1", KindQuery},
+		{"/* SELECT */ DROP TABLE t", KindDDL},
+		{"SELECT\n1", KindQuery},
+		{"SELECTED", KindUnknown},
+		{"SHOW VARIABLES", KindUnknown},
+		{"`SELECT`", KindUnknown},
+		{"", KindUnknown},
+		{"-- comment", KindUnknown},
+	} {
+		if got := (InputStatement{Statement: tt.statement}).Kind(); got != tt.want {
+			t.Errorf("Kind() of %q = %v, but want = %v", tt.statement, got, tt.want)
+		}
+	}
+}
+
+func TestFilterKind(t *testing.T) {
+	const input = "CREATE TABLE t (a INT64) PRIMARY KEY (a);\n" +
+		"INSERT INTO t (a) VALUES (1);\n" +
+		"-- comment\nALTER TABLE t ADD COLUMN b INT64\\G\n" +
+		"UPDATE t SET b = 1 WHERE TRUE;\n" +
+		"SELECT * FROM t"
+	for _, tt := range []struct {
+		kind StatementKind
+		want []string
+	}{
+		{KindDDL, []string{"CREATE TABLE t (a INT64) PRIMARY KEY (a)", "ALTER TABLE t ADD COLUMN b INT64"}},
+		{KindDML, []string{"INSERT INTO t (a) VALUES (1)", "UPDATE t SET b = 1 WHERE TRUE"}},
+		{KindQuery, []string{"SELECT * FROM t"}},
+		{KindDCL, nil},
+	} {
+		got := FilterKind(input, tt.kind, `\G`)
+		if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+			t.Errorf("%v: difference in statements: (-want +got):\n%s", tt.kind, diff)
+		}
+	}
+}
+
+func TestStatementKind_String(t *testing.T) {
+	for _, tt := range []struct {
+		kind StatementKind
+		want string
+	}{
+		{KindUnknown, "Unknown"},
+		{KindQuery, "Query"},
+		{KindDML, "DML"},
+		{KindDDL, "DDL"},
+		{KindDCL, "DCL"},
+		{StatementKind(100), "StatementKind(100)"},
+	} {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("StatementKind(%d).String() = %q, but want = %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
+
+func TestGroupTransactions(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  [][]string
+	}{
+		{
+			desc:  "explicit transactions and standalone statements",
+			input: "SELECT 1; BEGIN; INSERT INTO t (a) VALUES (1); UPDATE t SET a = 2 WHERE TRUE; COMMIT; SELECT 2; start transaction; DELETE FROM t WHERE TRUE; ROLLBACK;",
+			want: [][]string{
+				{"SELECT 1"},
+				{"BEGIN", "INSERT INTO t (a) VALUES (1)", "UPDATE t SET a = 2 WHERE TRUE", "COMMIT"},
+				{"SELECT 2"},
+				{"start transaction", "DELETE FROM t WHERE TRUE", "ROLLBACK"},
+			},
+		},
+		{
+			desc:  "keywords after comments",
+			input: "/* tx */ BEGIN TRANSACTION; SELECT 1; -- done\nCommit Transaction; SELECT 2",
+			want: [][]string{
+				{"BEGIN TRANSACTION", "SELECT 1", "Commit Transaction"},
+				{"SELECT 2"},
+			},
+		},
+		{
+			desc:  "START without TRANSACTION",
+			input: "START BATCH DDL; CREATE TABLE t (a INT64) PRIMARY KEY (a); COMMIT",
+			want: [][]string{
+				{"START BATCH DDL"},
+				{"CREATE TABLE t (a INT64) PRIMARY KEY (a)"},
+				{"COMMIT"},
+			},
+		},
+		{
+			desc:  "unclosed transaction",
+			input: "BEGIN; SELECT 1; SELECT 2",
+			want:  [][]string{{"BEGIN", "SELECT 1", "SELECT 2"}},
+		},
+		{
+			desc:  "empty input",
+			input: "",
+			want:  nil,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got [][]string
+			for _, group := range GroupTransactions(SeparateInput(tt.input)) {
+				got = append(got, statements(group))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in groups: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
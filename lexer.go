@@ -0,0 +1,71 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+// ConsumeStringLiteral consumes a GoogleSQL string or bytes literal at the beginning of s,
+// including its prefix like r, b, or rb and triple quotes.
+// It returns the literal as written and the rest of s.
+// ok is false if s doesn't begin with a literal, or the literal is not closed.
+func ConsumeStringLiteral(s string) (literal string, rest string, ok bool) {
+	sep := NewSeparator(s)
+	raw, bytes := false, false
+	for i := 0; i < 3 && i < len(s); i++ {
+		switch {
+		case !raw && (s[i] == 'r' || s[i] == 'R'):
+			raw = true
+			continue
+		case !bytes && (s[i] == 'b' || s[i] == 'B'):
+			bytes = true
+			continue
+		case s[i] == '"' || s[i] == '\'':
+			switch {
+			case raw && bytes:
+				sep.consumeRawBytesString()
+			case raw:
+				sep.consumeRawString()
+			case bytes:
+				sep.consumeBytesString()
+			default:
+				sep.consumeString()
+			}
+			return sep.consumed(s)
+		}
+		break
+	}
+	return "", s, false
+}
+
+// ConsumeQuotedIdentifier consumes a GoogleSQL quoted identifier at the beginning of s.
+// It returns the identifier as written, including backticks, and the rest of s.
+// ok is false if s doesn't begin with a backtick, or the identifier is not closed.
+func ConsumeQuotedIdentifier(s string) (identifier string, rest string, ok bool) {
+	if len(s) == 0 || s[0] != '`' {
+		return "", s, false
+	}
+	sep := NewSeparator(s)
+	sep.consumeQuotedIdentifier()
+	return sep.consumed(s)
+}
+
+// consumed returns the consumed token and the rest of input.
+// ok is false if the token is not closed, and input is returned as the rest.
+func (s *Separator) consumed(input string) (token string, rest string, ok bool) {
+	if s.currentDelimiter != "" {
+		return "", input, false
+	}
+	return s.sb.String(), s.str, true
+}
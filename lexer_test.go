@@ -0,0 +1,79 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import "testing"
+
+func TestConsumeStringLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		input    string
+		want     string
+		wantRest string
+		wantOK   bool
+	}{
+		{desc: "double quoted string", input: `"test" WHERE`, want: `"test"`, wantRest: " WHERE", wantOK: true},
+		{desc: "single quoted string", input: `'test' WHERE`, want: `'test'`, wantRest: " WHERE", wantOK: true},
+		{desc: "tripled quoted string", input: `"""test""" WHERE`, want: `"""test"""`, wantRest: " WHERE", wantOK: true},
+		{desc: "quoted string with escape sequence", input: `"te\"st" WHERE`, want: `"te\"st"`, wantRest: " WHERE", wantOK: true},
+		{desc: "double quoted empty string", input: `"" WHERE`, want: `""`, wantRest: " WHERE", wantOK: true},
+		{desc: "tripled quoted string with new line", input: "'''t\ne\ns\nt''' WHERE", want: "'''t\ne\ns\nt'''", wantRest: " WHERE", wantOK: true},
+		{desc: "triple quoted empty string", input: `"""""" WHERE`, want: `""""""`, wantRest: " WHERE", wantOK: true},
+		{desc: "multi-byte character in string", input: `"テスト" WHERE`, want: `"テスト"`, wantRest: " WHERE", wantOK: true},
+		{desc: "triple-quoted string with two consecutive quotes", input: `'''a''b''' WHERE`, want: `'''a''b'''`, wantRest: " WHERE", wantOK: true},
+		{desc: "triple-quoted string closed by the first triple quote in a run", input: `"""a""""" WHERE`, want: `"""a"""`, wantRest: `"" WHERE`, wantOK: true},
+		{desc: "triple-quoted string with an escaped quote before closing", input: `"""a\"""" WHERE`, want: `"""a\""""`, wantRest: " WHERE", wantOK: true},
+		{desc: "nine quotes", input: `"""""""""`, want: `""""""`, wantRest: `"""`, wantOK: true},
+		{desc: "raw string", input: `r"\"" WHERE`, want: `r"\"`, wantRest: `" WHERE`, wantOK: true},
+		{desc: "bytes string", input: `B'\'' WHERE`, want: `B'\''`, wantRest: " WHERE", wantOK: true},
+		{desc: "raw bytes string", input: `bR'''a'b''' WHERE`, want: `bR'''a'b'''`, wantRest: " WHERE", wantOK: true},
+		{desc: "unclosed string", input: `'test WHERE`, wantRest: `'test WHERE`},
+		{desc: "unclosed triple-quoted string", input: `"""test"" WHERE`, wantRest: `"""test"" WHERE`},
+		{desc: "not a string", input: "SELECT 'a'", wantRest: "SELECT 'a'"},
+		{desc: "prefix only", input: "rb", wantRest: "rb"},
+		{desc: "duplicated prefix", input: "rr'a'", wantRest: "rr'a'"},
+		{desc: "empty input", input: "", wantRest: ""},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, rest, ok := ConsumeStringLiteral(tt.input)
+			if got != tt.want || rest != tt.wantRest || ok != tt.wantOK {
+				t.Errorf("ConsumeStringLiteral(%q) = (%q, %q, %v), but want = (%q, %q, %v)", tt.input, got, rest, ok, tt.want, tt.wantRest, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestConsumeQuotedIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		want     string
+		wantRest string
+		wantOK   bool
+	}{
+		{"`a` WHERE", "`a`", " WHERE", true},
+		{"`a``b` WHERE", "`a``b`", " WHERE", true},
+		{"`a\\`b` WHERE", "`a\\`b`", " WHERE", true},
+		{"`a WHERE", "", "`a WHERE", false},
+		{"a` WHERE", "", "a` WHERE", false},
+		{"", "", "", false},
+	} {
+		got, rest, ok := ConsumeQuotedIdentifier(tt.input)
+		if got != tt.want || rest != tt.wantRest || ok != tt.wantOK {
+			t.Errorf("ConsumeQuotedIdentifier(%q) = (%q, %q, %v), but want = (%q, %q, %v)", tt.input, got, rest, ok, tt.want, tt.wantRest, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+const readChunkSize = 64 * 1024
+
+// lookaheadSize returns the upper bound of bytes the Separator looks ahead to decide a token.
+func lookaheadSize(terms []string) int {
+	lookahead := 3 // longest prefix of string literals or triple-quoted delimiter
+	for _, term := range terms {
+		if n := utf8.RuneCountInString(term); n > lookahead {
+			lookahead = n
+		}
+	}
+	return lookahead * utf8.UTFMax
+}
+
+// SeparateReader separates input read from r for each statement and returns []InputStatement.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+// If reading from r fails, statements separated before the failure are returned with the error.
+func SeparateReader(r io.Reader, customTerminators ...string) ([]InputStatement, error) {
+	var result []InputStatement
+	err := SeparateReaderFunc(r, func(stmt InputStatement) error {
+		result = append(result, stmt)
+		return nil
+	}, customTerminators...)
+	return result, err
+}
+
+// SeparateFile reads the file at path and separates its content for each statement as same as SeparateInput.
+// The content must be UTF-8, and a UTF-8 BOM at the beginning is skipped.
+// Input in other encodings can be separated by SeparateReaderWithDecoder.
+// If reading the file fails, it returns nil and the error.
+func SeparateFile(path string, customTerminators ...string) ([]InputStatement, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return SeparateInput(string(b), customTerminators...), nil
+}
+
+// SeparateReaderWithDecoder separates input read from r and decoded by dec for each statement,
+// and returns []InputStatement as same as SeparateReader.
+// Statements and their offsets are in UTF-8 decoded by dec, like charmap.Windows1252.NewDecoder() of golang.org/x/text,
+// so input in other encodings than UTF-8 can be separated without corrupting non-ASCII characters.
+// If decoding fails, statements separated before the failure are returned with the error.
+func SeparateReaderWithDecoder(r io.Reader, dec *encoding.Decoder, customTerminators ...string) ([]InputStatement, error) {
+	return SeparateReader(dec.Reader(r), customTerminators...)
+}
+
+// SeparateReaderFunc separates input read from r for each statement and calls fn for each statement in order.
+// Input is buffered only until the statement is terminated, so the whole input is not needed to be in memory.
+// If fn returns an error, SeparateReaderFunc stops and returns the error.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateReaderFunc(r io.Reader, fn func(stmt InputStatement) error, customTerminators ...string) error {
+	// Statements are emitted only if input after the terminator is enough to look ahead,
+	// so tokens spanning chunk boundaries are separated as same as SeparateInput.
+	lookahead := lookaheadSize(customTerminators)
+
+	var buf []byte
+	offset, line, column, index := 0, 1, 1, 0
+	// pending is the length of the beginning of buf which is already scanned without a complete statement.
+	pending := 0
+	chunk := make([]byte, readChunkSize)
+	s := newSeparator("", false, customTerminators)
+	for {
+		n, err := r.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if err == nil && len(buf) < 2*pending {
+			// scan again only after the buffer is doubled, so a long statement is scanned in amortized linear time.
+			continue
+		}
+		// bytes read with an error are also separated before returning the error.
+		atEOF := err == io.EOF
+
+		s.Reset(string(buf))
+		s.rebase(offset, line, column)
+		s.count = index
+		consumed, nextLine, nextColumn := 0, line, column
+		for {
+			stmt, ok := s.Next()
+			if !ok {
+				break
+			}
+			end := s.offset()
+			if !atEOF && (stmt.Terminator == "" || end+lookahead > len(buf)) {
+				break
+			}
+			if err := fn(stmt); err != nil {
+				return err
+			}
+			index++
+			consumed = end
+			nextLine, nextColumn = s.position(end)
+		}
+		if atEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		offset, line, column = offset+consumed, nextLine, nextColumn
+		buf = append(buf[:0], buf[consumed:]...)
+		pending = len(buf)
+	}
+}
@@ -0,0 +1,213 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestSeparateReader(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+		},
+		{
+			desc:  "multiple statements",
+			input: "SELECT 1;\nSELECT 2;\nSELECT 3",
+		},
+		{
+			desc:  "custom terminators",
+			input: "SELECT 1\\G\nSELECT 2;\n",
+		},
+		{
+			desc:  "strings and comments",
+			input: "SELECT '''a;\nb''';\n/* comment; */ SELECT `c;d` -- comment;\n;\nSELECT rb\"\\;\"",
+		},
+		{
+			desc:  "triple-quoted string",
+			input: `SELECT """a;"";""";SELECT 1`,
+		},
+		{
+			desc:  "multi-byte characters",
+			input: "SELECT 'テスト;';\nSELECT \"テスト\";",
+		},
+		{
+			desc:  "non-closed string",
+			input: "SELECT 1; SELECT '2;",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			want := SeparateInput(tt.input, `\G`)
+			for _, r := range []struct {
+				desc   string
+				reader io.Reader
+			}{
+				{"whole", strings.NewReader(tt.input)},
+				{"one byte", iotest.OneByteReader(strings.NewReader(tt.input))},
+				{"half", iotest.HalfReader(strings.NewReader(tt.input))},
+				{"data with EOF", iotest.DataErrReader(strings.NewReader(tt.input))},
+			} {
+				got, err := SeparateReader(r.reader, `\G`)
+				if err != nil {
+					t.Fatalf("%s: SeparateReader() returns error: %v", r.desc, err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("%s: difference in statements: (-want +got):\n%s", r.desc, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSeparateReader_Error(t *testing.T) {
+	errRead := errors.New("read error")
+	r := io.MultiReader(strings.NewReader("SELECT 1; SELECT 2; SELECT 3 FROM Singers"), iotest.ErrReader(errRead))
+	got, err := SeparateReader(r)
+	if !errors.Is(err, errRead) {
+		t.Errorf("SeparateReader() returns error %v, but want = %v", err, errRead)
+	}
+	want := []InputStatement{{Statement: "SELECT 1", Terminator: ";"}, {Statement: "SELECT 2", Terminator: ";"}}
+	if diff := cmp.Diff(want, got, ignorePositions); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+// dataErrReader returns the whole data with err by the first Read.
+type dataErrReader struct {
+	data string
+	err  error
+}
+
+func (r *dataErrReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	if r.data != "" {
+		return n, nil
+	}
+	return n, r.err
+}
+
+func TestSeparateReader_DataWithError(t *testing.T) {
+	errRead := errors.New("read error")
+	got, err := SeparateReader(&dataErrReader{data: "SELECT 1; SELECT 2; SELECT 3 FROM Singers", err: errRead})
+	if !errors.Is(err, errRead) {
+		t.Errorf("SeparateReader() returns error %v, but want = %v", err, errRead)
+	}
+	if diff := cmp.Diff([]string{"SELECT 1", "SELECT 2"}, statements(got)); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparateReader_LongStatements(t *testing.T) {
+	input := "SELECT 1;\nSELECT '" + strings.Repeat("a;", 3*readChunkSize) + "';\n" +
+		strings.Repeat("SELECT 2;", readChunkSize/4) + "SELECT `" + strings.Repeat("b", 5*readChunkSize) + "`"
+	got, err := SeparateReader(iotest.HalfReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("SeparateReader() returns error: %v", err)
+	}
+	if diff := cmp.Diff(SeparateInput(input), got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparateReaderFunc(t *testing.T) {
+	errStop := errors.New("stop")
+	var got []string
+	err := SeparateReaderFunc(iotest.OneByteReader(strings.NewReader("SELECT 1; SELECT 2; SELECT 3;")), func(stmt InputStatement) error {
+		got = append(got, stmt.Statement)
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("SeparateReaderFunc() returns error %v, but want = %v", err, errStop)
+	}
+	if diff := cmp.Diff([]string{"SELECT 1", "SELECT 2"}, got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparateReader_BOM(t *testing.T) {
+	input := "\uFEFFSELECT 1;\nSELECT 2;"
+	got, err := SeparateReader(iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("SeparateReader() returns error: %v", err)
+	}
+	if diff := cmp.Diff(SeparateInput(input), got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparateReaderWithDecoder(t *testing.T) {
+	// "SELECT 'café';\nSELECT 'ß' -- ü\n" in Latin-1
+	input := "SELECT 'caf\xe9';\nSELECT '\xdf' -- \xfc\n"
+	got, err := SeparateReaderWithDecoder(strings.NewReader(input), charmap.ISO8859_1.NewDecoder())
+	if err != nil {
+		t.Fatalf("SeparateReaderWithDecoder() returns error: %v", err)
+	}
+	want := []InputStatement{
+		{Statement: "SELECT 'café'", Terminator: ";", Start: 0, End: 14, Line: 1, Column: 1, TerminatorOffset: 14, Terminated: true},
+		{Statement: "SELECT 'ß'", Terminator: "", Start: 16, End: 27, Line: 2, Column: 1, TerminatorOffset: -1, Index: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.sql")
+	input := "\uFEFF-- comment\nSELECT 1;\nSELECT 2\\G\n"
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := SeparateFile(path, `\G`)
+	if err != nil {
+		t.Fatalf("SeparateFile() returns error: %v", err)
+	}
+	if diff := cmp.Diff(SeparateInput(input, `\G`), got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"SELECT 1", "SELECT 2"}, statements(got)); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+
+	got, err = SeparateFile(filepath.Join(t.TempDir(), "missing.sql"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("SeparateFile() of a missing file returns error %v, but want = %v", err, fs.ErrNotExist)
+	}
+	if got != nil {
+		t.Errorf("SeparateFile() of a missing file = %#v, but want = nil", got)
+	}
+}
@@ -0,0 +1,67 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import "bufio"
+
+// scanWindowSize is the initial size of data scanned by ScanStatements, which is doubled until a statement is found,
+// so a long buffer is not converted to a string for each short statement.
+const scanWindowSize = 512
+
+// ScanStatements returns a split function for bufio.Scanner which returns each statement as a token.
+// Tokens are same as SeparateInputString, so comments are stripped and terminators are not included.
+// Strings, comments, and quoted identifiers spanning buffer boundaries are handled correctly,
+// but a statement must fit in the buffer of bufio.Scanner, which can be extended by bufio.Scanner.Buffer.
+// A UTF-8 BOM is skipped only at the beginning of input, so the split function must not be shared by Scanners.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func ScanStatements(customTerminators ...string) bufio.SplitFunc {
+	lookahead := lookaheadSize(customTerminators)
+	s := newSeparator("", false, customTerminators)
+	started := false
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		for size := scanWindowSize; ; size *= 2 {
+			whole := size >= len(data)
+			if whole {
+				size = len(data)
+			}
+			s.Reset(string(data[:size]))
+			if started {
+				s.keepBOM()
+			}
+			stmt, ok := s.Next()
+			end := s.offset()
+			switch {
+			case ok && stmt.Terminator != "" && end+lookahead <= size:
+				started = true
+				return end, []byte(stmt.Statement), nil
+			case !whole:
+				// the statement may continue after the window
+				continue
+			case !ok && atEOF:
+				// only whitespaces and comments are remained
+				return len(data), nil, nil
+			case ok && atEOF:
+				started = true
+				return end, []byte(stmt.Statement), nil
+			default:
+				// request more data
+				return 0, nil, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScanStatements(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+		},
+		{
+			desc:  "only comments",
+			input: "-- comment\n/* comment */\n",
+		},
+		{
+			desc:  "multiple statements",
+			input: "SELECT 1;\nSELECT 2;\nSELECT 3",
+		},
+		{
+			desc:  "empty statements",
+			input: "SELECT 1;;\n;",
+		},
+		{
+			desc:  "custom terminators",
+			input: "SELECT 1\\G\nSELECT 2;\n",
+		},
+		{
+			desc:  "strings, comments, and quoted identifiers",
+			input: "SELECT '''a;\nb''';\n/* comment; */ SELECT `c;d` -- comment;\n;\nSELECT rb\"\\;\"; SELECT \"\"\"a;\"\";\"\"\"",
+		},
+		{
+			desc:  "multi-byte characters",
+			input: "SELECT 'テスト;';\nSELECT \"テスト\";",
+		},
+		{
+			desc:  "non-closed string",
+			input: "SELECT 1; SELECT '2;",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			want := SeparateInputString(tt.input, `\G`)
+			for _, r := range []struct {
+				desc   string
+				reader io.Reader
+			}{
+				{"whole", strings.NewReader(tt.input)},
+				{"one byte", iotest.OneByteReader(strings.NewReader(tt.input))},
+				{"half", iotest.HalfReader(strings.NewReader(tt.input))},
+			} {
+				scanner := bufio.NewScanner(r.reader)
+				// small buffer to exercise the refill path
+				scanner.Buffer(make([]byte, 4), bufio.MaxScanTokenSize)
+				scanner.Split(ScanStatements(`\G`))
+				var got []string
+				for scanner.Scan() {
+					got = append(got, scanner.Text())
+				}
+				if err := scanner.Err(); err != nil {
+					t.Fatalf("%s: Scan() returns error: %v", r.desc, err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("%s: difference in statements: (-want +got):\n%s", r.desc, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestScanStatements_BOM(t *testing.T) {
+	for _, input := range []string{
+		"\uFEFFSELECT 1;\nSELECT 2",
+		";\uFEFFx",
+		"SELECT 1;\uFEFFSELECT 2;\n\uFEFF",
+	} {
+		for _, r := range []struct {
+			desc   string
+			reader io.Reader
+		}{
+			{"whole", strings.NewReader(input)},
+			{"one byte", iotest.OneByteReader(strings.NewReader(input))},
+		} {
+			scanner := bufio.NewScanner(r.reader)
+			scanner.Split(ScanStatements())
+			var got []string
+			for scanner.Scan() {
+				got = append(got, scanner.Text())
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("%s: Scan() returns error: %v", r.desc, err)
+			}
+			if diff := cmp.Diff(SeparateInputString(input), got); diff != "" {
+				t.Errorf("%s: %q: difference in statements: (-want +got):\n%s", r.desc, input, diff)
+			}
+		}
+	}
+}
+
+func TestScanStatements_LongInput(t *testing.T) {
+	// statements longer and shorter than the initial window in a large buffer
+	input := strings.Repeat("SELECT 1;\n", 2000) + "SELECT '" + strings.Repeat("a;", 10*scanWindowSize) + "';\n" +
+		strings.Repeat("SELECT 2;", 2000) + "SELECT 3"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(ScanStatements())
+	var got []string
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan() returns error: %v", err)
+	}
+	if diff := cmp.Diff(SeparateInputString(input), got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import "fmt"
+
+// SegmentKind is a kind of a segment.
+type SegmentKind int
+
+const (
+	// SegmentStatement is a statement without its terminator.
+	SegmentStatement SegmentKind = iota
+	// SegmentTerminator is a terminator of the preceding statement.
+	SegmentTerminator
+)
+
+func (k SegmentKind) String() string {
+	switch k {
+	case SegmentStatement:
+		return "Statement"
+	case SegmentTerminator:
+		return "Terminator"
+	default:
+		return fmt.Sprintf("SegmentKind(%d)", int(k))
+	}
+}
+
+// Segment is a statement or a terminator in input.
+type Segment struct {
+	Kind SegmentKind
+	// Text is Statement of InputStatement for a statement, or the terminator.
+	Text string
+	// Start and End are byte offsets of the segment in the original input.
+	Start, End int
+}
+
+// SeparateWithTerminatorTokens separates input into statements and terminators, and returns them as []Segment in source order.
+// Each statement is followed by its terminator except the last statement which runs off the end of input,
+// so statements and terminators alternate. A statement can be empty, e.g. between consecutive terminators.
+// Statements are the same as SeparateInput, so all comments in input are stripped.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateWithTerminatorTokens(input string, customTerminators ...string) []Segment {
+	stmts := SeparateInput(input, customTerminators...)
+	if len(stmts) == 0 {
+		return nil
+	}
+	segments := make([]Segment, 0, 2*len(stmts))
+	for _, stmt := range stmts {
+		segments = append(segments, Segment{Kind: SegmentStatement, Text: stmt.Statement, Start: stmt.Start, End: stmt.End})
+		if stmt.Terminated {
+			segments = append(segments, Segment{
+				Kind:  SegmentTerminator,
+				Text:  stmt.Terminator,
+				Start: stmt.TerminatorOffset,
+				End:   stmt.TerminatorOffset + len(stmt.Terminator),
+			})
+		}
+	}
+	return segments
+}
@@ -0,0 +1,80 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeparateWithTerminatorTokens(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []Segment
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			want:  nil,
+		},
+		{
+			desc:  "mixed terminators",
+			input: "SELECT 1;\nSELECT 2\\G\nSELECT 3",
+			want: []Segment{
+				{Kind: SegmentStatement, Text: "SELECT 1", Start: 0, End: 8},
+				{Kind: SegmentTerminator, Text: ";", Start: 8, End: 9},
+				{Kind: SegmentStatement, Text: "SELECT 2", Start: 10, End: 18},
+				{Kind: SegmentTerminator, Text: `\G`, Start: 18, End: 20},
+				{Kind: SegmentStatement, Text: "SELECT 3", Start: 21, End: 29},
+			},
+		},
+		{
+			desc:  "empty statement",
+			input: "SELECT 1 /* ; */;;",
+			want: []Segment{
+				{Kind: SegmentStatement, Text: "SELECT 1", Start: 0, End: 8},
+				{Kind: SegmentTerminator, Text: ";", Start: 16, End: 17},
+				{Kind: SegmentStatement, Text: "", Start: 17, End: 17},
+				{Kind: SegmentTerminator, Text: ";", Start: 17, End: 18},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := SeparateWithTerminatorTokens(tt.input, `\G`)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in segments: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSegmentKind_String(t *testing.T) {
+	for _, tt := range []struct {
+		kind SegmentKind
+		want string
+	}{
+		{SegmentStatement, "Statement"},
+		{SegmentTerminator, "Terminator"},
+		{SegmentKind(100), "SegmentKind(100)"},
+	} {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("SegmentKind(%d).String() = %q, but want = %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
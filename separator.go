@@ -20,38 +20,136 @@
 package gsqlsep
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
 	"strings"
-
-	"golang.org/x/exp/slices"
+	"unicode"
+	"unicode/utf8"
 )
 
 type InputStatement struct {
-	Statement  string
-	Terminator string
+	Statement  string `json:"statement"`
+	Terminator string `json:"terminator"`
+
+	// Start and End are byte offsets of the statement in the original input, excluding the terminator
+	// even if WithKeepTerminator is enabled.
+	// Leading and trailing whitespace and comments are excluded.
+	// If the statement is empty, both of them are the offset of the terminator.
+	Start int `json:"start"`
+	End   int `json:"end"`
+
+	// Line and Column are 1-based line and column numbers of Start.
+	// Column is counted in runes.
+	Line   int `json:"line"`
+	Column int `json:"column"`
+
+	// TerminatorOffset is the byte offset of Terminator in the original input,
+	// or -1 if the statement is not terminated.
+	TerminatorOffset int `json:"terminator_offset"`
+
+	// Terminated reports whether the statement is terminated by a terminator.
+	// It is false for the last statement which runs off the end of input.
+	Terminated bool `json:"terminated"`
+
+	// LeadingComments is comments before the first token of the statement, if captured by WithLeadingComments.
+	LeadingComments []string `json:"leading_comments,omitempty"`
+	// TrailingComments is comments after the last token of the statement, if captured by WithTrailingComments.
+	TrailingComments []string `json:"trailing_comments,omitempty"`
+
+	// Placeholders is query parameters in the statement, if collected by WithCollectPlaceholders.
+	Placeholders []Placeholder `json:"placeholders,omitempty"`
+
+	// Index is the zero-based position of the statement in the returned statements,
+	// which counts empty statements unless they are skipped by WithSkipEmpty.
+	Index int `json:"index"`
+
+	// Source is the verbatim text of input from the end of the previous terminator to the end of the terminator,
+	// including comments and whitespaces, if enabled by WithSource.
+	Source string `json:"source,omitempty"`
+
+	// Header is the header comments before the first token of input, which is set only to the first statement
+	// if enabled by WithExtractHeader.
+	Header string `json:"header,omitempty"`
+}
+
+// Placeholder is a query parameter outside of strings, comments, and quoted identifiers.
+type Placeholder struct {
+	// Text is a named parameter like `@name` or a positional parameter `?`.
+	Text string `json:"text"`
+	// Offset is the byte offset of the placeholder in the original input.
+	Offset int `json:"offset"`
 }
 
+// Status is the status of the Separator at the end of input.
 type Status struct {
-	WaitingString string
+	// WaitingString is the closing delimiter the Separator is waiting for, or "" if nothing is waited.
+	WaitingString string `json:"waiting_string"`
+	// WaitingKind is the kind of the token which waits for WaitingString.
+	// It is encoded as its name like "string literal" in JSON.
+	WaitingKind WaitingKind `json:"waiting_kind"`
 }
 
-func (stmt *InputStatement) StripComments() InputStatement {
-	result := SeparateInputString(stmt.Statement)
-	if len(result) == 0 {
-		return InputStatement{
-			Statement:  "",
-			Terminator: stmt.Terminator,
-		}
+// WaitingKind is a kind of the token which waits for its closing delimiter.
+type WaitingKind int
+
+const (
+	// WaitingNone means no token is waiting for its closing delimiter.
+	WaitingNone WaitingKind = iota
+	// WaitingStringLiteral means input ends in a string literal or a bytes literal.
+	WaitingStringLiteral
+	// WaitingQuotedIdentifier means input ends in a quoted identifier.
+	WaitingQuotedIdentifier
+	// WaitingComment means input ends in a multiline comment.
+	WaitingComment
+)
+
+func (k WaitingKind) String() string {
+	switch k {
+	case WaitingNone:
+		return "none"
+	case WaitingStringLiteral:
+		return "string literal"
+	case WaitingQuotedIdentifier:
+		return "quoted identifier"
+	case WaitingComment:
+		return "comment"
+	default:
+		return fmt.Sprintf("WaitingKind(%d)", int(k))
+	}
+}
+
+// waitingKind returns the kind of the token which is closed by delim.
+func waitingKind(delim string) WaitingKind {
+	switch delim {
+	case "":
+		return WaitingNone
+	case "*/":
+		return WaitingComment
+	case "`":
+		return WaitingQuotedIdentifier
+	default:
+		return WaitingStringLiteral
 	}
+}
 
+func (stmt *InputStatement) StripComments() InputStatement {
+	result := *stmt
+	result.Statement = ""
 	// It can assume InputStatement.Statement doesn't have any terminating characters.
-	return InputStatement{
-		Statement:  result[0],
-		Terminator: stmt.Terminator,
+	if stmts := SeparateInputString(stmt.Statement); len(stmts) > 0 {
+		result.Statement = stmts[0]
 	}
+	return result
 }
 
 // SeparateInput separates input for each statement and returns []InputStatement.
 // This function strip all comments in input.
+// It returns nil, not an empty slice, if input has no statements, e.g. input is empty, whitespaces, or comments.
+// Each terminator terminates a statement even if it is empty, so `;;;` returns three empty statements
+// in both strip and preserve modes unless WithSkipEmpty is enabled.
 // By default, input will be separated by terminating semicolons `;`.
 // In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
 func SeparateInput(input string, customTerminators ...string) []InputStatement {
@@ -59,6 +157,124 @@ func SeparateInput(input string, customTerminators ...string) []InputStatement {
 	return stmts
 }
 
+// SeparateInputFunc separates input for each statement and calls fn for each statement in order.
+// If fn returns an error, SeparateInputFunc stops and returns the error.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateInputFunc(input string, fn func(stmt InputStatement) error, customTerminators ...string) error {
+	s := newSeparator(input, false, customTerminators)
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return nil
+		}
+		if err := fn(stmt); err != nil {
+			return err
+		}
+	}
+}
+
+// SeparateSQL separates input for each statement with opts and returns []InputStatement,
+// with the first error encountered like SeparateInputStrict if WithStrict is passed.
+// By default, input will be separated by terminating semicolons `;` and comments are stripped as same as SeparateInput.
+func SeparateSQL(input string, opts ...Option) ([]InputStatement, error) {
+	return NewSeparator(input, opts...).ReadAll()
+}
+
+// SeparateInputStrict separates input for each statement and returns []InputStatement.
+// Unlike SeparateInput, it returns *UnclosedError with statements before the error
+// if input ends in a string literal, a bytes literal, a quoted identifier, or a multiline comment,
+// and *InvalidEscapeError if a non-raw string or bytes literal has an invalid escape sequence.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateInputStrict(input string, customTerminators ...string) ([]InputStatement, error) {
+	return NewSeparator(input, WithTerminators(customTerminators...), WithStrict(true), WithStrictEscapes(true)).ReadAll()
+}
+
+// Validate reports the first structural problem of input as an error without returning statements.
+// It returns *UnclosedError if input ends in a string literal, a bytes literal, a quoted identifier, or a multiline comment,
+// *InvalidEscapeError if a non-raw string or bytes literal has an invalid escape sequence,
+// and *MissingTerminatorError if the last statement isn't terminated. Trailing whitespaces and comments are allowed.
+// It returns nil if every statement is terminated.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func Validate(input string, customTerminators ...string) error {
+	s := NewSeparator(input, WithTerminators(customTerminators...), WithStrict(true), WithStrictEscapes(true))
+	s.discard = true
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return s.Err()
+		}
+		if !stmt.Terminated {
+			return &MissingTerminatorError{Offset: stmt.End}
+		}
+	}
+}
+
+// IsComplete reports whether input is ready to be executed, like a statement typed in an interactive shell.
+// It returns true only if input has a statement, the last statement is terminated
+// followed by only whitespaces and comments, and no string, quoted identifier, or multiline comment is unclosed.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func IsComplete(input string, customTerminators ...string) bool {
+	s := newSeparator(input, false, customTerminators)
+	s.discard = true
+	terminated := false
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return terminated && s.Status().WaitingString == ""
+		}
+		terminated = stmt.Terminated
+	}
+}
+
+// SeparateInputContext separates input for each statement and returns []InputStatement as same as SeparateInput,
+// but it returns early with ctx.Err() when ctx is done. Statements separated before that are also returned.
+func SeparateInputContext(ctx context.Context, input string, customTerminators ...string) ([]InputStatement, error) {
+	s := newSeparator(input, false, customTerminators)
+	s.ctx = ctx
+	return s.ReadAll()
+}
+
+// contextCheckInterval is the number of tokens scanned between checks of the context.
+const contextCheckInterval = 1024
+
+// SeparateInputWithStatus separates input for each statement and returns []InputStatement and Status.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateInputWithStatus(input string, customTerminators ...string) ([]InputStatement, Status) {
+	return newSeparator(input, false, customTerminators).separate()
+}
+
+// SeparatePartial separates input, which is a part of the larger input, for each terminated statement,
+// and returns them as complete. This function strip all comments in complete.
+// tail is the verbatim text of input after the last terminator, which is not yet a complete statement,
+// so it should be prepended to the next part of input. tail is "" if it consists solely of whitespaces.
+// status is the status at the end of input, e.g. tail ends in a string literal.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparatePartial(input string, customTerminators ...string) (complete []InputStatement, tail string, status Status) {
+	s := newSeparator(input, false, customTerminators)
+	end := 0
+	for {
+		stmt, ok := s.Next()
+		if !ok || !stmt.Terminated {
+			break
+		}
+		complete = append(complete, stmt)
+		end = s.offset()
+	}
+	if tail = input[end:]; strings.TrimSpace(tail) == "" {
+		tail = ""
+	}
+	return complete, tail, s.Status()
+}
+
 // SeparateInputString separates input for each statement and returns []string.
 // This function strip all comments in input.
 // By default, input will be separated by terminating semicolons `;`.
@@ -71,6 +287,106 @@ func SeparateInputString(input string, customTerminators ...string) []string {
 	return result
 }
 
+// Range is a statement text and its byte offsets in the original input.
+type Range struct {
+	Text       string
+	Start, End int
+}
+
+// SeparateInputRanges separates input for each statement and returns []Range.
+// Text, Start, and End are the same as Statement, Start, and End of InputStatement returned by SeparateInput.
+// This function strip all comments in input, so input[Start:End] differs from Text if the statement has inner comments.
+func SeparateInputRanges(input string, customTerminators ...string) []Range {
+	var result []Range
+	for _, s := range SeparateInput(input, customTerminators...) {
+		result = append(result, Range{Text: s.Statement, Start: s.Start, End: s.End})
+	}
+	return result
+}
+
+// StripComments removes all comments in input and returns the remaining input.
+// Each comment is replaced by a single whitespace as same as the separating functions,
+// and all other texts including whitespaces, terminators, strings, and quoted identifiers are left intact.
+func StripComments(input string) string {
+	var sb strings.Builder
+	sb.Grow(len(input))
+	s := NewSeparator(input, WithTrimSpace(false))
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return sb.String()
+		}
+		sb.WriteString(stmt.Statement)
+		sb.WriteString(stmt.Terminator)
+	}
+}
+
+// Join concatenates Statement and Terminator of each statement.
+// If stmts are separated with WithPreserveComments(true) and WithTrimSpace(false), Join reproduces the original input,
+// except a UTF-8 BOM and commands consumed by WithDelimiterCommand.
+// Otherwise, comments and whitespaces around statements are lost, but the structure of statements is preserved
+// so that Join can be separated again into the same statements.
+// stmts should not be separated with WithKeepTerminator(true) because their Statement already has their Terminator.
+func Join(stmts []InputStatement) string {
+	var n int
+	for _, stmt := range stmts {
+		n += len(stmt.Statement) + len(stmt.Terminator)
+	}
+	var sb strings.Builder
+	sb.Grow(n)
+	for _, stmt := range stmts {
+		sb.WriteString(stmt.Statement)
+		sb.WriteString(stmt.Terminator)
+	}
+	return sb.String()
+}
+
+// WriteSeparated separates input for each statement as same as SeparateInput, and writes each statement followed by sep, like `;\n`,
+// to w in order, so original terminators are replaced by sep. Empty statements are not written.
+// If writing to w fails, WriteSeparated stops and returns the error.
+func WriteSeparated(w io.Writer, input string, sep string, customTerminators ...string) error {
+	s := NewSeparator(input, WithTerminators(customTerminators...), WithSkipEmpty(true))
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return nil
+		}
+		if _, err := io.WriteString(w, stmt.Statement+sep); err != nil {
+			return err
+		}
+	}
+}
+
+// CountStatements returns the number of statements which SeparateInput returns, including empty statements.
+// It doesn't allocate texts of statements.
+func CountStatements(input string, customTerminators ...string) int {
+	s := newSeparator(input, false, customTerminators)
+	s.discard = true
+	var n int
+	for {
+		if _, ok := s.Next(); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// TerminatorStats returns the number of statements which SeparateInput returns for each terminator,
+// including empty statements. The key "" counts the last statement without a terminator.
+// It doesn't allocate texts of statements.
+func TerminatorStats(input string, customTerminators ...string) map[string]int {
+	s := newSeparator(input, false, customTerminators)
+	s.discard = true
+	stats := make(map[string]int)
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return stats
+		}
+		stats[stmt.Terminator]++
+	}
+}
+
 // SeparateInputPreserveComments separates input for each statement and returns []InputStatement.
 // This function preserve comments in input.
 // By default, input will be separated by terminating semicolons `;`.
@@ -85,8 +401,7 @@ func SeparateInputPreserveComments(input string, customTerminators ...string) []
 // By default, input will be separated by terminating semicolons `;`.
 // In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
 func SeparateInputPreserveCommentsWithStatus(input string, customTerminators ...string) ([]InputStatement, Status) {
-	stmts, currentDelimiter := newSeparator(input, true, customTerminators).separate()
-	return stmts, Status{WaitingString: currentDelimiter}
+	return newSeparator(input, true, customTerminators).separate()
 }
 
 // SeparateInputStringPreserveComments separates input for each statement and returns []string.
@@ -101,273 +416,2124 @@ func SeparateInputStringPreserveComments(input string, customTerminators ...stri
 	return result
 }
 
-type separator struct {
-	str []rune // remaining input
-	sb  *strings.Builder
+// Separator separates input into statements one at a time.
+// Call Next repeatedly to get statements until it reports false.
+type Separator struct {
+	str string // remaining input
+	// sb is the buffer of the current statement. It is reused across statements.
+	sb *bytes.Buffer
 	// terms is custom terminators.
-	// It isn't []string to minimize string-rune conversions.
-	terms            [][]rune
+	terms            []string
 	preserveComments bool
+	dollarQuoting    bool
+	hashComments     bool
+	nestedComments   bool
+	strict           bool
+	keepTerminator   bool
+	trimSpace        bool
+	delimiterCommand bool
+	batchSeparator   string
+	skipEmpty        bool
+	escapeString     bool
+	typedLiterals    bool
+	// warnBackslash is called with a backslash-letter sequence which doesn't match any terminator.
+	warnBackslash func(seq string, offset int)
+	// lineContinuation reports whether a backslash followed by a newline is removed.
+	lineContinuation bool
+	// rawStrings and bytesStrings report whether prefixes of raw strings and bytes literals are recognized.
+	rawStrings   bool
+	bytesStrings bool
+	// validateUTF8 reports whether input is validated as UTF-8,
+	// and invalidUTF8 is the byte offset of the first invalid byte in input, or -1 if none or not validated.
+	validateUTF8 bool
+	invalidUTF8  int
+	// canonicalTerms maps matched terminators to their canonical forms.
+	canonicalTerms map[string]string
+	// bracketAware and parenAware report whether terminators are ignored in brackets or only in parentheses,
+	// and depth is the nesting level.
+	bracketAware bool
+	parenAware   bool
+	depth        int
+	// blockBegin and blockEnd are keywords of blocks in which terminators are ignored, and blockDepth is the nesting level.
+	// endCase is the byte offset of `CASE` in `END CASE`, which doesn't open a block.
+	blockBegin, blockEnd string
+	blockDepth           int
+	endCase              int
+	// blankLineSeparator reports whether blank lines are treated as a statement boundary.
+	blankLineSeparator bool
+	// collapseSpace reports whether runs of whitespaces are collapsed to a single space.
+	collapseSpace bool
+	// extractHeader reports whether header comments are extracted, headerPending reports whether they are not yet,
+	// header is the extracted header, and headerStmt reports whether it is to be set to the next statement.
+	extractHeader bool
+	headerPending bool
+	header        string
+	headerStmt    bool
+	// empty reports whether the last flushed statement has no text other than trimmed characters and its terminator.
+	empty bool
+	// terminatorSpace reports whether whitespaces before terminators are kept.
+	terminatorSpace bool
+	// transform is applied to each statement before it is returned.
+	transform func(InputStatement) InputStatement
+	// hintAwareness reports whether a hint `@{...}` is consumed as a token.
+	hintAwareness bool
+	// collectPlaceholders reports whether placeholders are collected into placeholders.
+	collectPlaceholders bool
+	placeholders        []Placeholder
+	// dropTrailingCommentOnly reports whether a comment-only statement at the end of input is dropped.
+	dropTrailingCommentOnly bool
+	// trimFunc reports whether a rune is trimmed around statements. nil means Unicode whitespaces.
+	trimFunc func(rune) bool
+	// lineCommentPrefixes is additional prefixes of single line comments.
+	lineCommentPrefixes []string
+	// dashCommentRequireSpace reports whether `--` must be followed by a whitespace to start a comment.
+	dashCommentRequireSpace bool
+	// directives is handlers of single line comments starting with their prefixes.
+	directives []directive
+	// initial is the status to resume from at the beginning of input.
+	initial Status
+	// source reports whether InputStatement.Source is populated, and sourceStart is the offset of the current source.
+	source      bool
+	sourceStart int
+	// commentIndent reports whether the indent of the leading comment is kept,
+	// and commentFirst reports whether the current statement starts with a preserved comment.
+	commentIndent bool
+	commentFirst  bool
+	// annotations is handlers of `--` comments starting with their prefixes.
+	annotations []annotation
+	// commentReplacement replaces each stripped comment.
+	commentReplacement string
+	// blockCommentOpen and blockCommentClose are delimiters of multiline comments.
+	blockCommentOpen, blockCommentClose string
+	// termRegexp is the terminator pattern anchored at the beginning of the remaining input.
+	termRegexp *regexp.Regexp
+	// strictEscapes reports whether escape sequences in non-raw strings and bytes literals are validated.
+	strictEscapes bool
+	// caseInsensitiveTerms reports whether custom terminators are matched case-insensitively.
+	caseInsensitiveTerms bool
+	// wordBoundaryTerms reports whether custom terminators are matched only at word boundaries.
+	wordBoundaryTerms bool
+	// delimiter is the current primary terminator replacing semicolons, which can be changed by DELIMITER command.
+	// It is empty if there is no primary terminator.
+	delimiter string
+	// primary is the primary terminator configured by options, which delimiter is reset to.
+	primary string
+	// leadingComments reports whether comments before the first token of each statement are captured.
+	leadingComments bool
+	// leading is the captured leading comments of the current statement.
+	leading []string
+	// trailingComments reports whether comments after the last token of each statement are captured.
+	trailingComments bool
+	// trailing is the comments after the last token of the current statement.
+	// They are also written to the buffer from trailingAt, and removed from the buffer when the statement is flushed.
+	trailing []string
+	// trailingAt and trailingEnd are the buffer length and the end offset of the statement before trailing.
+	trailingAt, trailingEnd int
+	// hasToken reports whether the current statement has any token other than whitespaces and comments.
+	hasToken         bool
 	currentDelimiter string
-}
+	// openOffset is the byte offset of the token which waits currentDelimiter.
+	openOffset int
+	err        error
 
-func newSeparator(s string, preserveComment bool, terms []string) *separator {
-	var runeTerms [][]rune
-	for _, term := range terms {
-		runeTerms = append(runeTerms, []rune(term))
-	}
-	return &separator{
-		str:              []rune(s),
-		sb:               &strings.Builder{},
-		terms:            runeTerms,
-		preserveComments: preserveComment,
+	// maxStatements is the limit of the number of statements. It is not limited if zero.
+	maxStatements int
+	// count is the number of statements returned by Next, including the one exceeding maxStatements.
+	count int
+
+	// maxStatementLength is the limit of the length of a statement in runes. It is not limited if zero.
+	maxStatementLength int
+	// runes is the number of runes in the buffer except leading whitespaces, which is counted only if maxStatementLength is set.
+	runes int
+
+	// ctx is checked periodically to cancel separation if not nil.
+	ctx context.Context
+	// steps is the number of scanned tokens, which is used to check ctx periodically.
+	steps int
+
+	// tokenFn is called for each token if not nil.
+	tokenFn func(token Token)
+
+	// discard reports whether texts of statements are discarded to avoid allocations.
+	discard bool
+
+	input string // original input
+	// inputStart is the byte offset of input after a skipped BOM.
+	inputStart int
+	// lines is the cursor to calculate line and column numbers.
+	lines struct {
+		offset, line, column int
 	}
+	// start and end are byte offsets of the current statement. start is -1 if no content is written.
+	start, end int
+	// base is the byte offset of input in the larger input.
+	base int
 }
 
-func (s *separator) consumeRawString() {
-	// consume 'r' or 'R'
-	s.sb.WriteRune(s.str[0])
-	s.str = s.str[1:]
+// Option configures a Separator.
+type Option func(*Separator)
 
-	delim := s.consumeStringDelimiter()
-	s.consumeStringContent(delim, true)
+// WithPreserveComments configures whether comments in input are preserved in statements.
+// If comments are preserved and input ends in comments after the last terminator,
+// they are returned as a statement without a terminator unless WithDropTrailingCommentOnly is enabled.
+// If comments are stripped, they are trimmed as whitespaces, so no extra statement is returned.
+// By default, comments are stripped.
+func WithPreserveComments(preserve bool) Option {
+	return func(s *Separator) {
+		s.preserveComments = preserve
+	}
 }
 
-func (s *separator) consumeBytesString() {
-	// consume 'b' or 'B'
-	s.sb.WriteRune(s.str[0])
-	s.str = s.str[1:]
+// WithTerminators adds custom terminators, which are treated as terminating semicolons.
+// Terminators are never matched in strings, quoted identifiers, and comments.
+// A terminator starting with a quote `'`, `"`, or a backtick is never matched because it starts a string or a quoted identifier.
+// Duplicated terminators and the primary terminator are ignored, and empty terminators are also ignored.
+// Operators are not recognized, so a terminator like `|` or `>` also matches in the pipe operator `|>`
+// and the concatenation operator `||`, and it should be avoided for queries using them.
+func WithTerminators(terms ...string) Option {
+	return func(s *Separator) {
+		s.terms = append(s.terms, terms...)
+	}
+}
 
-	delim := s.consumeStringDelimiter()
-	s.consumeStringContent(delim, false)
+// WithVerticalTerminator adds the MySQL-style vertical terminator `\G` as a custom terminator.
+// It is the same as WithTerminators(`\G`).
+func WithVerticalTerminator() Option {
+	return WithTerminators(`\G`)
 }
 
-func (s *separator) consumeRawBytesString() {
-	// consume 'rb', 'Rb', 'rB', or 'RB'
-	s.sb.WriteRune(s.str[0])
-	s.sb.WriteRune(s.str[1])
-	s.str = s.str[2:]
+// WithPrimaryTerminator configures the primary terminator which replaces terminating semicolons, like `/`.
+// It is handled as same as semicolons, and semicolons become ordinary characters.
+// Note that it is matched anywhere outside of strings, comments, and quoted identifiers,
+// so `/` also splits a division like `SELECT 4/2`.
+// If term is empty, there is no primary terminator, e.g., to terminate statements only by
+// `/` on its own line as in Oracle with WithBatchSeparator("/"), which keeps divisions intact.
+// By default, the primary terminator is `;`.
+func WithPrimaryTerminator(term string) Option {
+	return func(s *Separator) {
+		s.delimiter = term
+	}
+}
 
-	delim := s.consumeStringDelimiter()
-	s.consumeStringContent(delim, true)
+// WithDollarQuoting configures whether PostgreSQL-style dollar-quoted strings `$tag$...$tag$` are recognized.
+// The tag can be empty like `$$...$$`. By default, dollar-quoted strings are not recognized.
+func WithDollarQuoting(enabled bool) Option {
+	return func(s *Separator) {
+		s.dollarQuoting = enabled
+	}
 }
 
-func (s *separator) consumeString() {
-	delim := s.consumeStringDelimiter()
-	s.consumeStringContent(delim, false)
+// WithHashComments configures whether `#` is recognized as a single line comment.
+// If disabled, `#` is treated as an ordinary character. By default, `#` comments are recognized.
+func WithHashComments(enabled bool) Option {
+	return func(s *Separator) {
+		s.hashComments = enabled
+	}
 }
 
-func (s *separator) consumeStringContent(delim string, raw bool) {
-	var i int
-	for i < len(s.str) {
-		// check end of string
-		if hasStringPrefix(s.str[i:], delim) {
-			s.str = s.str[i+len(delim):]
-			s.sb.WriteString(delim)
-			s.currentDelimiter = ""
-			return
-		}
+// WithNestedComments configures whether multiline comments can be nested like `/* /* */ */`.
+// If enabled, a multiline comment is terminated only when its nesting depth returns to zero.
+// By default, nested comments are not supported, as in Spanner.
+func WithNestedComments(enabled bool) Option {
+	return func(s *Separator) {
+		s.nestedComments = enabled
+	}
+}
 
-		// escape sequence
-		if s.str[i] == '\\' {
-			if raw {
-				// raw string treats escape character as backslash
-				s.sb.WriteRune('\\')
-				i++
-				continue
+// WithLineCommentPrefixes adds prefixes of single line comments like `//`, in addition to `#` and `--`.
+// If multiple prefixes match at the same position, the longest one is used, including `/*` of multiline comments.
+// Empty prefixes are ignored. Comments by them are preserved or replaced as same as other comments.
+func WithLineCommentPrefixes(prefixes ...string) Option {
+	return func(s *Separator) {
+		for _, p := range prefixes {
+			if p != "" {
+				s.lineCommentPrefixes = append(s.lineCommentPrefixes, p)
 			}
+		}
+	}
+}
 
-			// invalid escape sequence
-			if i+1 >= len(s.str) {
-				s.sb.WriteRune('\\')
-				s.currentDelimiter = delim
-				return
-			}
+// WithDashCommentRequireSpace configures whether `--` starts a single line comment only if it is followed by
+// a whitespace or the end of input, as in MySQL. If enabled, `5 --3` is an expression rather than `5` and a comment,
+// and a run of dashes starts a comment only at the last two dashes followed by a whitespace, e.g. `a --- b` is `a -`.
+// By default, `--` always starts a comment at the first two dashes of a run, e.g. `a---b` is `a` and a comment `---b`.
+func WithDashCommentRequireSpace(require bool) Option {
+	return func(s *Separator) {
+		s.dashCommentRequireSpace = require
+	}
+}
 
-			s.sb.WriteRune('\\')
-			s.sb.WriteRune(s.str[i+1])
-			i += 2
-			continue
-		}
-		s.sb.WriteRune(s.str[i])
-		i++
+// WithDirectivePrefix configures a handler of directives in single line comments like `-- +migrate Up` or `#!`.
+// If a single line comment starts with prefix including its comment marker, fn is called with the comment
+// without its line terminator, and the comment is stripped even if comments are preserved.
+// It can be passed multiple times for different prefixes, and the first matching one is used.
+func WithDirectivePrefix(prefix string, fn func(line string)) Option {
+	return func(s *Separator) {
+		s.directives = append(s.directives, directive{prefix: prefix, fn: fn})
 	}
-	s.str = s.str[i:]
-	s.currentDelimiter = delim
-	return
 }
 
-func (s *separator) consumeStringDelimiter() string {
-	c := s.str[0]
-	// check triple-quoted delim
-	if delim := strings.Repeat(string(c), 3); hasStringPrefix(s.str, delim) {
-		s.sb.WriteString(delim)
-		s.str = s.str[len(delim):]
-		return delim
+// WithAnnotationPrefix configures a handler of annotations in `--` comments like `-- name: GetUser :one` of sqlc.
+// If a `--` comment starts with prefix after optional whitespaces, fn is called with the first word after prefix as name
+// and the remaining text as rest, both trimmed, and the comment is stripped even if comments are preserved.
+// It can be passed multiple times for different prefixes, and directives by WithDirectivePrefix take precedence.
+func WithAnnotationPrefix(prefix string, fn func(name, rest string)) Option {
+	return func(s *Separator) {
+		s.annotations = append(s.annotations, annotation{prefix: prefix, fn: fn})
 	}
-	s.str = s.str[1:]
-	s.sb.WriteRune(c)
-	return string(c)
 }
 
-func (s *separator) skipComments() {
-	var i int
-	for i < len(s.str) {
-		var terminate string
-		if prefix := "#"; hasStringPrefix(s.str, prefix) {
-			// single line comment "#"
-			terminate = "\n"
-			i += len(prefix)
-		} else if prefix := "--"; hasStringPrefix(s.str, prefix) {
-			// single line comment "--"
-			terminate = "\n"
-			i += len(prefix)
-		} else if prefix := "/*"; hasStringPrefix(s.str, prefix) {
-			// multi line comments "/* */"
-			// NOTE: Nested multiline comments are not supported in Spanner.
-			// https://cloud.google.com/spanner/docs/lexical#multiline_comments
-			terminate = "*/"
-			s.currentDelimiter = terminate
-			i += len(prefix)
-		} else {
-			// out of comment
-			return
-		}
+// annotation is a handler of `--` comments starting with prefix.
+type annotation struct {
+	prefix string
+	fn     func(name, rest string)
+}
 
-		// not terminated, but end of string
-		if lenStr := len(s.str); i >= lenStr {
-			if s.preserveComments {
-				s.sb.WriteString(string(s.str))
-			}
-			s.str = s.str[lenStr:]
-			return
-		}
+// directive is a handler of single line comments starting with prefix.
+type directive struct {
+	prefix string
+	fn     func(line string)
+}
 
-		for ; i < len(s.str); i++ {
-			if lenT := len(terminate); hasStringPrefix(s.str[i:], terminate) {
-				if s.preserveComments {
-					s.sb.WriteString(string(s.str[:i+lenT]))
-				} else {
-					// always replace a comment to a single whitespace.
-					s.sb.WriteRune(' ')
-				}
-				s.str = s.str[i+lenT:]
-				i = 0
-				s.currentDelimiter = ""
-				break
-			}
-		}
+// WithInitialState configures the status to resume from, like Status returned for the previous part of input,
+// so the Separator starts as if it is in the middle of the token waiting for status.WaitingString.
+// The rest of the token at the beginning of input is a part of the first statement.
+// A resumed string literal honors backslash escapes even if it is a raw string, and nesting of comments is not resumed.
+// It is also applied after Reset.
+// By default, the Separator starts outside of any token.
+func WithInitialState(status Status) Option {
+	return func(s *Separator) {
+		s.initial = status
+	}
+}
 
-		// not terminated, but end of string
-		if lenStr := len(s.str); i >= lenStr {
-			if s.preserveComments {
-				s.sb.WriteString(string(s.str))
-			}
-			s.str = s.str[lenStr:]
-			return
-		}
+// WithSource configures whether InputStatement.Source is populated with the verbatim text of input for each statement.
+// Concatenating Source of all statements reproduces input except a UTF-8 BOM, unless statements are skipped
+// by WithSkipEmpty or WithDropTrailingCommentOnly, or input ends in whitespaces or stripped comments after the last terminator.
+// Such trailing text yields no statement, so it is not included in any Source, e.g. ` -- bye` of `SELECT 1; -- bye`.
+// By default, Source is empty.
+func WithSource(enabled bool) Option {
+	return func(s *Separator) {
+		s.source = enabled
 	}
 }
 
-// separate separates input string into multiple Spanner statements.
-// This does not validate syntax of statements.
-//
-// NOTE: Logic for parsing a statement is mostly taken from spansql.
-// https://github.com/googleapis/google-cloud-go/blob/master/spanner/spansql/parser.go
-func (s *separator) separate() ([]InputStatement, string) {
-	var statements []InputStatement
-	for len(s.str) > 0 {
-		s.skipComments()
-		if len(s.str) == 0 {
-			break
-		}
+// WithPreserveCommentIndent configures whether whitespaces before a preserved comment at the beginning of a statement
+// are kept in its line, like `   -- comment` in `SELECT 1;\n   -- comment\nSELECT 2;`, so a formatter can re-emit its alignment.
+// Line terminators before the line and trailing whitespaces are still trimmed, and Start is not changed.
+// It only takes effect if comments are preserved by WithPreserveComments and whitespaces are trimmed by WithTrimSpace.
+// By default, the whitespaces are trimmed.
+func WithPreserveCommentIndent(preserve bool) Option {
+	return func(s *Separator) {
+		s.commentIndent = preserve
+	}
+}
 
-		switch s.str[0] {
-		// possibly string literal
-		case '"', '\'', 'r', 'R', 'b', 'B':
-			// valid string prefix: "b", "B", "r", "R", "br", "bR", "Br", "BR"
-			// https://cloud.google.com/spanner/docs/lexical#string_and_bytes_literals
-			raw, bytes, str := false, false, false
-			for i := 0; i < 3 && i < len(s.str); i++ {
-				switch {
-				case !raw && (s.str[i] == 'r' || s.str[i] == 'R'):
-					raw = true
-					continue
-				case !bytes && (s.str[i] == 'b' || s.str[i] == 'B'):
-					bytes = true
-					continue
-				case s.str[i] == '"' || s.str[i] == '\'':
-					str = true
-					switch {
-					case raw && bytes:
-						s.consumeRawBytesString()
-					case raw:
-						s.consumeRawString()
-					case bytes:
-						s.consumeBytesString()
-					default:
-						s.consumeString()
-					}
-				}
-				break
-			}
-			if !str {
-				s.sb.WriteRune(s.str[0])
-				s.str = s.str[1:]
-			}
-		// quoted identifier
-		case '`':
-			s.sb.WriteRune(s.str[0])
-			s.str = s.str[1:]
-			s.consumeStringContent("`", false)
-		// horizontal delim
-		case ';':
-			statements = append(statements, InputStatement{
-				Statement:  strings.TrimSpace(s.sb.String()),
-				Terminator: ";",
-			})
-			s.sb.Reset()
-			s.str = s.str[1:]
-		default:
-			// TODO: may need some optimization
-			var found bool
-			for _, term := range s.terms {
-				if hasPrefix(s.str, term) {
-					statements = append(statements, InputStatement{
-						Statement:  strings.TrimSpace(s.sb.String()),
-						Terminator: string(term),
-					})
-					s.sb.Reset()
-					s.str = s.str[len(term):]
-					found = true
-					break
-				}
-			}
+// WithCommentReplacement configures the replacement of each stripped comment, like "" to remove comments completely.
+// It is not used if comments are preserved. A single line comment is replaced including its line terminator.
+// By default, each comment is replaced by a single whitespace so that it still separates tokens.
+func WithCommentReplacement(replacement string) Option {
+	return func(s *Separator) {
+		s.commentReplacement = replacement
+	}
+}
 
-			if !found {
-				s.sb.WriteRune(s.str[0])
-				s.str = s.str[1:]
-			}
+// WithBlockCommentDelimiters configures delimiters of multiline comments instead of `/*` and `*/`, like `{-` and `-}`.
+// Nesting by WithNestedComments also uses the configured delimiters.
+// If open or close is empty, the option is ignored.
+// By default, multiline comments are `/* */`.
+func WithBlockCommentDelimiters(open, close string) Option {
+	return func(s *Separator) {
+		if open == "" || close == "" {
+			return
 		}
+		s.blockCommentOpen, s.blockCommentClose = open, close
 	}
+}
 
-	// flush remained
-	if s.sb.Len() > 0 {
-		if str := strings.TrimSpace(s.sb.String()); len(str) > 0 {
-			statements = append(statements, InputStatement{
-				Statement:  str,
-				Terminator: "",
-			})
-			s.sb.Reset()
-		}
+// WithRawStrings configures whether the prefix `r` or `R` of raw string literals like `r"..."` is recognized.
+// If disabled, the prefix is treated as an ordinary character, and the following string is consumed as a non-raw string,
+// which honors backslash escapes. It also applies to raw bytes literals like `rb"..."`.
+// By default, raw string literals are recognized.
+func WithRawStrings(enabled bool) Option {
+	return func(s *Separator) {
+		s.rawStrings = enabled
+	}
+}
+
+// WithBytesStrings configures whether the prefix `b` or `B` of bytes literals like `b"..."` is recognized.
+// If disabled, the prefix is treated as an ordinary character, and the following string is consumed as a string literal.
+// It also applies to raw bytes literals like `rb"..."`.
+// By default, bytes literals are recognized.
+func WithBytesStrings(enabled bool) Option {
+	return func(s *Separator) {
+		s.bytesStrings = enabled
 	}
-	return statements, s.currentDelimiter
 }
 
-func hasPrefix(s, prefix []rune) bool {
-	return len(s) >= len(prefix) && slices.Equal(s[0:len(prefix)], prefix)
+// WithValidateUTF8 configures whether input is validated as UTF-8.
+// If input has an invalid byte, statements before the one containing it are returned,
+// and then Next returns false, and Err and ReadAll return InvalidUTF8Error.
+// Use SeparateReaderWithDecoder to separate input in other encodings.
+// By default, input is not validated, and invalid bytes are kept as is in statements.
+func WithValidateUTF8(validate bool) Option {
+	return func(s *Separator) {
+		s.validateUTF8 = validate
+	}
+}
+
+// WithStrictEscapes configures whether escape sequences in non-raw string and bytes literals are validated,
+// like `\xHH`, `\uHHHH`, `\UHHHHHHHH`, and `\ooo`. Raw literals are not validated.
+// If an invalid escape sequence is found, Next returns false, and Err and ReadAll return InvalidEscapeError.
+// By default, escape sequences are not validated.
+func WithStrictEscapes(strict bool) Option {
+	return func(s *Separator) {
+		s.strictEscapes = strict
+	}
 }
 
-func hasStringPrefix(s []rune, prefix string) bool {
-	return hasPrefix(s, []rune(prefix))
+// WithWarnUnknownBackslash configures fn to be called with a backslash-letter sequence like `\g`
+// outside of strings, comments, and quoted identifiers which doesn't match any terminator, and its byte offset.
+// It is purely diagnostic for typos of terminators like `\G`, and the sequence is consumed as ordinary characters.
+// By default, no function is called.
+func WithWarnUnknownBackslash(fn func(seq string, offset int)) Option {
+	return func(s *Separator) {
+		s.warnBackslash = fn
+	}
+}
+
+// WithLineContinuation configures whether a backslash immediately followed by a newline outside of strings,
+// comments, and quoted identifiers is removed with the newline, so the next line continues the statement.
+// Terminators are matched first, so `\G` is still a terminator if configured.
+// By default, a backslash is treated as an ordinary character.
+func WithLineContinuation(enabled bool) Option {
+	return func(s *Separator) {
+		s.lineContinuation = enabled
+	}
+}
+
+// WithCanonicalTerminator configures canonical forms of terminators stored in InputStatement.Terminator.
+// If a matched terminator is a key of canonical, the value is stored instead, like `\G` for `\g`.
+// Statement is left untouched even if WithKeepTerminator is enabled, and terminators not in canonical are stored as is.
+// By default, matched terminators are stored as is.
+func WithCanonicalTerminator(canonical map[string]string) Option {
+	return func(s *Separator) {
+		s.canonicalTerms = canonical
+	}
+}
+
+// WithBracketAwareTerminators configures whether terminators are ignored while inside parentheses `(...)`
+// or square brackets `[...]`, which are counted outside of strings, comments, and quoted identifiers.
+// Both kinds of brackets are counted as the same nesting level, and an unmatched closing bracket is ignored.
+// A statement with an unclosed bracket continues to the end of input.
+// By default, terminators are matched regardless of brackets.
+func WithBracketAwareTerminators(enabled bool) Option {
+	return func(s *Separator) {
+		s.bracketAware = enabled
+	}
+}
+
+// WithExtractHeader configures whether the contiguous run of comments and whitespaces before the first token of input,
+// like a license header, is extracted as InputStatement.Header of the first statement, trimmed.
+// The header is excluded from the statement even if comments are preserved, and not in InputStatement.LeadingComments.
+// It is also available by Separator.Header, e.g. for input without statements.
+// Unclosed comments are not treated as the header.
+// By default, header comments are a part of the first statement.
+func WithExtractHeader(enabled bool) Option {
+	return func(s *Separator) {
+		s.extractHeader = enabled
+	}
+}
+
+// WithCollapseWhitespace configures whether each run of ASCII whitespaces in statements is collapsed to a single space,
+// e.g. `SELECT\n\t1` becomes `SELECT 1`. Whitespaces in strings, quoted identifiers, and preserved comments are kept as is,
+// and a whitespace replacement of a stripped comment is collapsed together with surrounding whitespaces.
+// It is independent of trimming the both ends of statements by WithTrimSpace.
+// By default, whitespaces are kept as is.
+func WithCollapseWhitespace(enabled bool) Option {
+	return func(s *Separator) {
+		s.collapseSpace = enabled
+	}
+}
+
+// WithBlankLineSeparator configures whether one or more consecutive blank lines outside of strings and comments
+// are treated as a statement boundary. A line consisting solely of whitespaces is a blank line.
+// InputStatement.Terminator of the statement terminated by blank lines is empty, but it is Terminated.
+// Blank lines before the first token of a statement don't separate statements.
+// By default, blank lines are ordinary whitespaces.
+func WithBlankLineSeparator(enabled bool) Option {
+	return func(s *Separator) {
+		s.blankLineSeparator = enabled
+	}
+}
+
+// WithBlockKeywords configures keywords of blocks like `BEGIN ... END` in which terminators are ignored,
+// so the whole block is a single statement. Keywords are matched case-insensitively at word boundaries
+// outside of strings, comments, and quoted identifiers, and blocks can be nested.
+// If end is `END`, `CASE` is also counted as begin so that `END` of `CASE ... END` pairs with it,
+// and `END` followed by `IF`, `LOOP`, `WHILE`, `REPEAT`, or `FOR` is not counted as end.
+// Note that begin is counted even if it doesn't start a block, e.g. `BEGIN TRANSACTION`.
+// A statement with an unclosed block continues to the end of input.
+// By default, terminators are matched regardless of blocks.
+func WithBlockKeywords(begin, end string) Option {
+	return func(s *Separator) {
+		s.blockBegin, s.blockEnd = begin, end
+	}
+}
+
+// WithParenAwareTerminator configures whether terminators are ignored while inside parentheses `(...)`,
+// which are counted outside of strings, comments, and quoted identifiers, e.g. `CREATE TABLE t (a INT; b INT)`
+// is a single statement. It is the same as WithBracketAwareTerminators, except that square brackets are not counted.
+// An unmatched closing parenthesis is ignored, and a statement with an unclosed parenthesis continues to the end of input.
+// By default, terminators are matched regardless of parentheses.
+func WithParenAwareTerminator(enabled bool) Option {
+	return func(s *Separator) {
+		s.parenAware = enabled
+	}
+}
+
+// WithStatementTransform configures fn to be applied to each statement before it is returned.
+// fn receives the statement after trimming, and empty statements skipped by WithSkipEmpty are not passed.
+// The returned statement replaces the original one, so fn can rewrite any field.
+// By default, statements are returned as is.
+func WithStatementTransform(fn func(InputStatement) InputStatement) Option {
+	return func(s *Separator) {
+		s.transform = fn
+	}
+}
+
+// WithHintAwareness configures whether a hint like `@{OPTIMIZER_VERSION=1}` is recognized as a token
+// with balanced braces, so terminators in it are ignored. Strings in the hint are consumed as usual.
+// The hint is kept in the statement, and an unclosed hint continues to the end of input.
+// By default, a hint is treated as ordinary characters.
+func WithHintAwareness(enabled bool) Option {
+	return func(s *Separator) {
+		s.hintAwareness = enabled
+	}
+}
+
+// WithCollectPlaceholders configures whether query parameters are collected in InputStatement.Placeholders.
+// Named parameters like `@name` and positional parameters `?` outside of strings, comments,
+// and quoted identifiers are collected, but system variables like `@@name` are not.
+// A named parameter can be a quoted identifier like @`my param`, and its Text includes the backticks.
+// By default, placeholders are not collected.
+func WithCollectPlaceholders(collect bool) Option {
+	return func(s *Separator) {
+		s.collectPlaceholders = collect
+	}
+}
+
+// WithDropTrailingCommentOnly configures whether a statement consisting only of comments and whitespaces
+// at the end of input, like ` -- bye` in `SELECT 1; -- bye`, is dropped instead of returned without a terminator.
+// It affects only WithPreserveComments, WithLeadingComments, and WithTrimSpace(false),
+// because such a statement is never returned otherwise.
+// By default, the statement is returned.
+func WithDropTrailingCommentOnly(drop bool) Option {
+	return func(s *Separator) {
+		s.dropTrailingCommentOnly = drop
+	}
+}
+
+// WithLeadingComments configures whether comments before the first token of each statement are captured
+// in InputStatement.LeadingComments instead of InputStatement.Statement, regardless of WithPreserveComments.
+// Line terminators of single line comments are not included in captured comments.
+// If input ends in comments after the last terminator, they are returned as an empty statement without a terminator.
+// By default, leading comments are not captured.
+func WithLeadingComments(capture bool) Option {
+	return func(s *Separator) {
+		s.leadingComments = capture
+	}
+}
+
+// WithTrailingComments configures whether comments after the last token of each statement are captured
+// in InputStatement.TrailingComments instead of InputStatement.Statement, regardless of WithPreserveComments.
+// For example, `/* note */` of `SELECT 1 /* note */;` is captured.
+// Line terminators of single line comments are not included in captured comments.
+// By default, trailing comments are not captured.
+func WithTrailingComments(capture bool) Option {
+	return func(s *Separator) {
+		s.trailingComments = capture
+	}
+}
+
+// WithStrict configures whether the Separator reports an error for input which ends in
+// a string literal, a quoted identifier, or a multiline comment.
+// The error can be retrieved by Err. By default, such an unclosed token is returned as a part of the last statement.
+func WithStrict(strict bool) Option {
+	return func(s *Separator) {
+		s.strict = strict
+	}
+}
+
+// WithPreserveTerminatorWhitespace configures whether whitespaces between the last token of a statement
+// and its terminator are kept in InputStatement.Statement, e.g. `SELECT 1   ;` is exactly reconstructed
+// with WithKeepTerminator. Whitespaces at the end of input without a terminator are still trimmed.
+// InputStatement.End is not affected, and it is still the end of the last token.
+// By default, they are trimmed as same as other whitespaces around statements.
+func WithPreserveTerminatorWhitespace(preserve bool) Option {
+	return func(s *Separator) {
+		s.terminatorSpace = preserve
+	}
+}
+
+// WithKeepTerminator configures whether InputStatement.Statement includes its terminator.
+// Whitespaces and comments before the terminator are still removed, and InputStatement.Terminator is still populated.
+// Whitespaces before a terminator starting with a word character, like a batch separator `GO`, are kept
+// so that the terminator doesn't fuse with the statement. A single space, or a line break for a batch separator,
+// is inserted if there are no such whitespaces.
+// By default, InputStatement.Statement doesn't include its terminator.
+func WithKeepTerminator(keep bool) Option {
+	return func(s *Separator) {
+		s.keepTerminator = keep
+	}
+}
+
+// WithTrimSpace configures whether leading and trailing whitespaces of each statement are trimmed.
+// If disabled, all whitespaces are preserved, and trailing whitespaces after the last terminator
+// are returned as a statement without a terminator,
+// so joining statements and terminators reproduces input if comments are preserved.
+// By default, whitespaces are trimmed.
+func WithTrimSpace(trim bool) Option {
+	return func(s *Separator) {
+		s.trimSpace = trim
+	}
+}
+
+// WithTypedLiterals configures whether keyword-prefixed string literals like `DATE '2020-01-01'` are recognized.
+// The keyword is one of DATE, DATETIME, TIME, TIMESTAMP, NUMERIC, BIGNUMERIC, JSON, and INTERVAL case-insensitively,
+// and it can be separated from the string by whitespaces.
+// A recognized literal is tokenized as a single TokenTypedLiteral by Tokenize, and the string is consumed as usual,
+// so it doesn't change how statements are separated.
+// By default, the keyword is treated as ordinary characters.
+func WithTypedLiterals(enabled bool) Option {
+	return func(s *Separator) {
+		s.typedLiterals = enabled
+	}
+}
+
+// WithTrimCutset configures the characters trimmed around each statement as Unicode code points in cutset.
+// It only takes effect if whitespaces are trimmed by WithTrimSpace, and Start and End of statements follow it.
+// Characters not in cutset, e.g. a non-breaking space, are kept as a part of the statement even if they are whitespaces.
+// By default, all Unicode whitespaces are trimmed.
+func WithTrimCutset(cutset string) Option {
+	return func(s *Separator) {
+		s.trimFunc = func(r rune) bool {
+			return strings.ContainsRune(cutset, r)
+		}
+	}
+}
+
+// WithDelimiterCommand configures whether MySQL-style `DELIMITER <token>` command is recognized.
+// The command must be at the beginning of a statement and continues to the end of the line, case-insensitively.
+// It is consumed without emitting a statement, and the token replaces terminating semicolons until changed again.
+// By default, the command is not recognized.
+func WithDelimiterCommand(enabled bool) Option {
+	return func(s *Separator) {
+		s.delimiterCommand = enabled
+	}
+}
+
+// WithBatchSeparator configures a T-SQL style batch separator like `GO`.
+// A line consisting solely of word and optional whitespaces is treated as a terminator, case-insensitively.
+// InputStatement.Terminator of the statement terminated by it is the uppercased word.
+// It coexists with other terminators, so a batch separator just after a terminated statement
+// is returned as an empty statement with the word as a boundary marker, unless WithSkipEmpty is enabled.
+// By default, no batch separator is recognized.
+func WithBatchSeparator(word string) Option {
+	return func(s *Separator) {
+		s.batchSeparator = word
+	}
+}
+
+// WithSkipEmpty configures whether statements which are empty after trimming whitespaces,
+// or characters configured by WithTrimCutset, are omitted.
+// Terminators of omitted statements are still consumed.
+// By default, empty statements like the second statement of `SELECT 1; ;` are returned.
+func WithSkipEmpty(skip bool) Option {
+	return func(s *Separator) {
+		s.skipEmpty = skip
+	}
+}
+
+// WithEscapeStringPrefix configures whether standard SQL escape strings like `E'...'` are recognized.
+// The prefix `E` or `e` must not follow a part of an identifier, and backslash escapes are honored in the string.
+// By default, the prefix is treated as an ordinary character, and the following string is consumed
+// as a GoogleSQL string, which also honors backslash escapes.
+func WithEscapeStringPrefix(enabled bool) Option {
+	return func(s *Separator) {
+		s.escapeString = enabled
+	}
+}
+
+// WithTerminatorRegexp configures a terminator pattern in addition to custom terminators, like `\\[gG]`.
+// The pattern is matched at the beginning of each token outside of strings, quoted identifiers, and comments,
+// and the matched text is used as InputStatement.Terminator. Empty matches are ignored.
+// Custom terminators passed to WithTerminators take precedence over the pattern.
+// If re is nil, no pattern is used. By default, no pattern is used.
+func WithTerminatorRegexp(re *regexp.Regexp) Option {
+	return func(s *Separator) {
+		s.termRegexp = nil
+		if re != nil {
+			s.termRegexp = regexp.MustCompile(`^(?:` + re.String() + `)`)
+		}
+	}
+}
+
+// WithMaxStatements limits the number of statements to n.
+// If input has more statements, Next returns false instead of the (n+1)-th statement,
+// and Err and ReadAll return TooManyStatementsError.
+// If n is zero or negative, the number is not limited. By default, the number is not limited.
+func WithMaxStatements(n int) Option {
+	return func(s *Separator) {
+		s.maxStatements = n
+	}
+}
+
+// WithMaxStatementLength limits the length of each statement to n runes, excluding leading whitespaces.
+// If a statement exceeds the limit before its terminator is found, Next returns false,
+// and Err and ReadAll return StatementTooLongError.
+// It guards against a statement which swallows the rest of input like an unclosed string.
+// If n is zero or negative, the length is not limited. By default, the length is not limited.
+func WithMaxStatementLength(n int) Option {
+	return func(s *Separator) {
+		s.maxStatementLength = n
+	}
+}
+
+// WithWordBoundaryTerminators configures whether custom terminators are matched only at word boundaries,
+// so that a terminator like `go` doesn't match a part of an identifier like `google`.
+// A terminator starting with a letter, a digit, or an underscore must not be preceded by such a character,
+// and one ending with such a character must not be followed by such a character. Other terminators like `\G` are not affected.
+// Terminators matched case-insensitively by WithCaseInsensitiveTerminators are always matched at word boundaries.
+// By default, custom terminators are matched anywhere outside of strings, comments, and quoted identifiers.
+func WithWordBoundaryTerminators(enabled bool) Option {
+	return func(s *Separator) {
+		s.wordBoundaryTerms = enabled
+	}
+}
+
+// WithCaseInsensitiveTerminators configures whether custom terminators are matched case-insensitively.
+// The built-in terminating semicolon is not affected.
+// A terminator starting or ending with a letter, a digit, or an underscore is matched only at word boundaries
+// so that it doesn't match a part of an identifier, e.g. `go` doesn't match `ago` or `gopher`.
+// InputStatement.Terminator is the terminator as passed to WithTerminators, not as written in input.
+// By default, custom terminators are matched case-sensitively.
+func WithCaseInsensitiveTerminators(enabled bool) Option {
+	return func(s *Separator) {
+		s.caseInsensitiveTerms = enabled
+	}
+}
+
+// NewSeparator returns a new Separator to separate input.
+// input must be UTF-8, as all functions taking a string in this package. Invalid bytes are kept as is
+// unless WithValidateUTF8 is enabled, and input in other encodings should be decoded like SeparateReaderWithDecoder.
+// A UTF-8 BOM at the beginning of input is skipped.
+// By default, input will be separated by terminating semicolons `;` and comments are stripped.
+func NewSeparator(input string, opts ...Option) *Separator {
+	s := &Separator{
+		sb: &bytes.Buffer{},
+
+		hashComments: true,
+		trimSpace:    true,
+		delimiter:    ";",
+		rawStrings:   true,
+		bytesStrings: true,
+
+		blockCommentOpen:   "/*",
+		blockCommentClose:  "*/",
+		commentReplacement: " ",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.primary = s.delimiter
+	s.terms = uniqueTerminators(s.terms, s.primary)
+	s.Reset(input)
+	return s
+}
+
+// uniqueTerminators returns terms without duplicates, empty terminators, and the primary terminator, keeping the order.
+// An empty terminator would match at every position without consuming input.
+func uniqueTerminators(terms []string, primary string) []string {
+	result := terms[:0]
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		if term == "" || term == primary || seen[term] {
+			continue
+		}
+		seen[term] = true
+		result = append(result, term)
+	}
+	return result
+}
+
+// Reset discards the state of the Separator and makes it separate input with the same options.
+// The buffer of the Separator is reused.
+func (s *Separator) Reset(input string) {
+	s.str, s.input = input, input
+	s.sb.Reset()
+	s.delimiter = s.primary
+	s.hasToken = false
+	s.currentDelimiter = ""
+	s.openOffset = 0
+	s.err = nil
+	s.count, s.steps, s.runes = 0, 0, 0
+	s.leading, s.trailing = nil, nil
+	s.placeholders = nil
+	s.depth, s.blockDepth = 0, 0
+	s.endCase = -1
+	s.commentFirst = false
+	s.invalidUTF8 = -1
+	if s.validateUTF8 {
+		s.invalidUTF8 = invalidUTF8Offset(input)
+	}
+	s.start, s.end, s.base = -1, 0, 0
+	s.lines.offset, s.lines.line, s.lines.column = 0, 1, 1
+	// skip a UTF-8 BOM only at the beginning of input. Offsets are still relative to input.
+	if strings.HasPrefix(s.str, bom) {
+		s.str = s.str[len(bom):]
+		s.lines.offset = len(bom)
+	}
+	s.inputStart = s.offset()
+	s.sourceStart = s.offset()
+	s.header, s.headerStmt = "", false
+	s.headerPending = s.extractHeader && s.initial.WaitingString == ""
+	s.resume(s.initial)
+}
+
+// Header returns the header comments extracted by WithExtractHeader, after Next is called.
+func (s *Separator) Header() string {
+	return s.header
+}
+
+// consumeHeader consumes whitespaces and comments at the beginning of input as the header.
+func (s *Separator) consumeHeader() {
+	str := s.str
+	for {
+		pos := s.offset()
+		s.str = strings.TrimLeftFunc(s.str, unicode.IsSpace)
+		if s.offset() > pos {
+			s.emitToken(TokenOther, pos)
+		}
+		n := len(s.str)
+		s.skipComments()
+		if len(s.str) == n {
+			break
+		}
+	}
+	if s.currentDelimiter != "" {
+		// an unclosed comment is a part of the statement.
+		s.str, s.currentDelimiter = str, ""
+	} else {
+		s.header = strings.TrimSpace(str[:len(str)-len(s.str)])
+		s.headerStmt = true
+	}
+	s.sb.Reset()
+	s.leading, s.trailing = nil, nil
+	s.commentFirst = false
+}
+
+// resume consumes the rest of the token waiting for its closing delimiter in status at the beginning of input.
+func (s *Separator) resume(status Status) {
+	delim := status.WaitingString
+	if delim == "" {
+		return
+	}
+	kind := status.WaitingKind
+	if kind == WaitingNone {
+		s.currentDelimiter = delim
+		kind = s.waitingKind()
+		s.currentDelimiter = ""
+	}
+	pos, n := s.offset(), s.sb.Len()
+	switch kind {
+	case WaitingComment:
+		s.currentDelimiter, s.openOffset = delim, pos
+		if i := strings.Index(s.str, delim); i >= 0 {
+			s.writeComment(s.str[:i+len(delim)], s.str[:i+len(delim)], true, true)
+			s.str = s.str[i+len(delim):]
+			s.currentDelimiter = ""
+		} else {
+			s.writeComment(s.str, s.str, true, false)
+			s.str = ""
+		}
+		if s.preserveComments {
+			s.track(pos, n)
+		}
+		return
+	case WaitingQuotedIdentifier:
+		s.consumeQuotedIdentifierContent()
+	default:
+		s.consumeStringContent(delim, false)
+	}
+	s.openOffset = pos
+	s.hasToken = true
+	s.track(pos, n)
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid UTF-8 byte in str, or -1 if str is valid.
+func invalidUTF8Offset(str string) int {
+	for i := 0; i < len(str); {
+		if str[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// bom is the UTF-8 byte order mark.
+const bom = "\uFEFF"
+
+func newSeparator(s string, preserveComment bool, terms []string) *Separator {
+	return NewSeparator(s, WithPreserveComments(preserveComment), WithTerminators(terms...))
+}
+
+func (s *Separator) consumeRawString() {
+	// consume 'r' or 'R'
+	s.sb.WriteByte(s.str[0])
+	s.str = s.str[1:]
+
+	delim := s.consumeStringDelimiter()
+	s.consumeStringContent(delim, true)
+}
+
+func (s *Separator) consumeBytesString() {
+	// consume 'b' or 'B'
+	s.sb.WriteByte(s.str[0])
+	s.str = s.str[1:]
+
+	delim := s.consumeStringDelimiter()
+	s.consumeEscapedStringContent(delim, true)
+}
+
+func (s *Separator) consumeRawBytesString() {
+	if len(s.str) < 3 || (s.str[2] != '\'' && s.str[2] != '"') {
+		// not followed by a quote, so the prefix is consumed as ordinary characters.
+		n := 2
+		if len(s.str) < n {
+			n = len(s.str)
+		}
+		s.sb.WriteString(s.str[:n])
+		s.str = s.str[n:]
+		return
+	}
+	// consume 'rb', 'rB', 'Rb', 'RB', 'br', 'bR', 'Br', or 'BR' as written
+	s.sb.WriteString(s.str[:2])
+	s.str = s.str[2:]
+
+	delim := s.consumeStringDelimiter()
+	s.consumeStringContent(delim, true)
+}
+
+func (s *Separator) consumeString() {
+	delim := s.consumeStringDelimiter()
+	s.consumeEscapedStringContent(delim, false)
+}
+
+// consumeEscapedStringContent consumes the content of a non-raw string or bytes literal,
+// and validates its escape sequences if strictEscapes is enabled.
+func (s *Separator) consumeEscapedStringContent(delim string, bytes bool) {
+	pos, n := s.offset(), s.sb.Len()
+	s.consumeStringContent(delim, false)
+	if s.strictEscapes && s.err == nil {
+		s.validateEscapes(s.sb.Bytes()[n:], pos, bytes)
+	}
+}
+
+// validateEscapes sets InvalidEscapeError for the first invalid escape sequence in content at pos.
+// Escape sequences of GoogleSQL: https://cloud.google.com/spanner/docs/reference/standard-sql/lexical#escape_sequences
+func (s *Separator) validateEscapes(content []byte, pos int, bytes bool) {
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\\' {
+			continue
+		}
+		if i+1 >= len(content) {
+			// a backslash at the end of unclosed literal
+			return
+		}
+		n, ok := escapeLength(content[i+1:], bytes)
+		if !ok {
+			_, size := utf8.DecodeRune(content[i+1:])
+			s.err = &InvalidEscapeError{Offset: s.base + pos + i, Sequence: string(content[i : i+1+size])}
+			return
+		}
+		i += n
+	}
+}
+
+// escapeLength returns the length of the escape sequence at the beginning of b after a backslash.
+// ok is false if the escape sequence is invalid. \u and \U are not allowed in bytes literals.
+func escapeLength(b []byte, bytes bool) (n int, ok bool) {
+	switch c := b[0]; c {
+	case 'a', 'b', 'f', 'n', 'r', 't', 'v', '\\', '?', '"', '\'', '`':
+		return 1, true
+	case 'x', 'X':
+		return 3, len(b) >= 3 && isHex(b[1]) && isHex(b[2])
+	case 'u', 'U':
+		digits := 4
+		if c == 'U' {
+			digits = 8
+		}
+		if bytes || len(b) < 1+digits {
+			return 0, false
+		}
+		var r rune
+		for _, d := range b[1 : 1+digits] {
+			if !isHex(d) {
+				return 0, false
+			}
+			r = r<<4 | rune(hexValue(d))
+		}
+		return 1 + digits, utf8.ValidRune(r)
+	case '0', '1', '2', '3':
+		// octal escape sequences are exactly 3 digits up to \377
+		return 3, len(b) >= 3 && isOctal(b[1]) && isOctal(b[2])
+	default:
+		return 0, false
+	}
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isOctal(c byte) bool {
+	return '0' <= c && c <= '7'
+}
+
+func (s *Separator) consumeQuotedIdentifier() {
+	// consume '`'
+	s.sb.WriteByte(s.str[0])
+	s.str = s.str[1:]
+	s.consumeQuotedIdentifierContent()
+}
+
+// consumeQuotedIdentifierContent consumes the content of a quoted identifier after the opening backtick.
+func (s *Separator) consumeQuotedIdentifierContent() {
+	for {
+		// backslash escape sequences like "\`" are handled as same as strings
+		s.consumeStringContent("`", false)
+		// doubled backtick "``" is also an escaped backtick
+		if s.currentDelimiter != "" || len(s.str) == 0 || s.str[0] != '`' {
+			return
+		}
+		s.sb.WriteByte(s.str[0])
+		s.str = s.str[1:]
+	}
+}
+
+func (s *Separator) consumeDollarQuotedString(delim string) {
+	s.sb.WriteString(delim)
+	s.str = s.str[len(delim):]
+	// dollar-quoted strings don't have escape sequences
+	s.consumeStringContent(delim, true)
+}
+
+func (s *Separator) consumeStringContent(delim string, raw bool) {
+	var i int
+	for i < len(s.str) {
+		// check end of string
+		if strings.HasPrefix(s.str[i:], delim) {
+			s.sb.WriteString(s.str[:i+len(delim)])
+			s.str = s.str[i+len(delim):]
+			s.currentDelimiter = ""
+			return
+		}
+
+		// escape sequence
+		if s.str[i] == '\\' {
+			if raw {
+				// raw string treats escape character as backslash
+				i++
+				continue
+			}
+
+			// invalid escape sequence
+			if i+1 >= len(s.str) {
+				i++
+				break
+			}
+
+			// multi-byte characters can be skipped bytewise because UTF-8 continuation bytes never match delim.
+			i += 2
+			continue
+		}
+		i++
+	}
+	s.sb.WriteString(s.str[:i])
+	s.str = s.str[i:]
+	s.currentDelimiter = delim
+	return
+}
+
+// consumeStringDelimiter consumes the opening delimiter of a string, and returns it.
+// The remaining input must start with a quote, which is checked by callers.
+func (s *Separator) consumeStringDelimiter() string {
+	// check triple-quoted delim
+	if len(s.str) >= 3 && s.str[1] == s.str[0] && s.str[2] == s.str[0] {
+		delim := s.str[:3]
+		s.sb.WriteString(delim)
+		s.str = s.str[3:]
+		return delim
+	}
+	delim := s.str[:1]
+	s.sb.WriteString(delim)
+	s.str = s.str[1:]
+	return delim
+}
+
+func (s *Separator) skipComments() {
+	if len(s.str) == 0 || (len(s.lineCommentPrefixes) == 0 && s.str[0] != '#' && s.str[0] != '-' && s.str[0] != s.blockCommentOpen[0]) {
+		// fast path for not comments
+		return
+	}
+	var i int
+	for i < len(s.str) {
+		prefix, terminate, block := s.commentPrefix()
+		if prefix == "" {
+			// out of comment
+			return
+		}
+		i += len(prefix)
+		if block {
+			s.currentDelimiter = terminate
+			s.openOffset = s.offset()
+		}
+
+		// not terminated, but end of string
+		if lenStr := len(s.str); i >= lenStr {
+			s.writeComment(s.str, s.str, block, false)
+			s.str = s.str[lenStr:]
+			return
+		}
+
+		depth := 1
+		for ; i < len(s.str); i++ {
+			if prefix := s.blockCommentOpen; s.nestedComments && block && strings.HasPrefix(s.str[i:], prefix) {
+				depth++
+				i += len(prefix) - 1
+				continue
+			}
+			if !block && s.str[i] == '\r' {
+				// single line comments are also terminated by "\r\n" or "\r" as same as ZetaSQL.
+				// "\r\n" is consumed as a single newline.
+				terminate = "\r"
+				if strings.HasPrefix(s.str[i:], "\r\n") {
+					terminate = "\r\n"
+				}
+			}
+			if lenT := len(terminate); strings.HasPrefix(s.str[i:], terminate) {
+				if depth--; depth > 0 {
+					i += lenT - 1
+					continue
+				}
+				text := s.str[:i+lenT]
+				if !block {
+					// a line terminator is not a part of a single line comment
+					text = s.str[:i]
+				}
+				s.writeComment(s.str[:i+lenT], text, block, true)
+				s.str = s.str[i+lenT:]
+				i = 0
+				s.currentDelimiter = ""
+				break
+			}
+		}
+
+		// not terminated, but end of string
+		if lenStr := len(s.str); i >= lenStr {
+			s.writeComment(s.str, s.str, block, false)
+			s.str = s.str[lenStr:]
+			return
+		}
+	}
+}
+
+// writeComment writes comment to the buffer, or captures text of comment as a leading or trailing comment.
+// block reports whether comment is a multiline comment, and closed reports whether comment is closed.
+// If comments are stripped and comment is closed, comment is replaced by commentReplacement.
+func (s *Separator) writeComment(comment, text string, block, closed bool) {
+	if comment == "" {
+		return
+	}
+	if s.tokenFn != nil {
+		pos := s.offset()
+		s.tokenFn(Token{Kind: TokenComment, Text: comment, Start: s.base + pos, End: s.base + pos + len(comment)})
+	}
+	if !block && s.handleDirective(text) {
+		// a directive is always stripped
+		if closed {
+			s.sb.WriteString(s.commentReplacement)
+		}
+		return
+	}
+	if s.leadingComments && !s.hasToken {
+		s.leading = append(s.leading, text)
+		return
+	}
+	if s.trailingComments && s.hasToken {
+		// it is a trailing comment unless another token follows.
+		if len(s.trailing) == 0 {
+			s.trailingAt, s.trailingEnd = s.sb.Len(), s.end
+		}
+		s.trailing = append(s.trailing, text)
+	}
+	if s.preserveComments {
+		if s.commentIndent && !s.hasToken && len(s.trim(s.sb.Bytes())) == 0 {
+			s.commentFirst = true
+		}
+		s.sb.WriteString(comment)
+	} else if closed && s.collapseSpace && strings.TrimLeft(s.commentReplacement, " \t\n\v\f\r") == "" {
+		s.writeSpace()
+	} else if closed {
+		// replace a comment to a single whitespace by default.
+		s.sb.WriteString(s.commentReplacement)
+	}
+}
+
+// writeSpace writes a single space unless the buffer already ends with a space.
+func (s *Separator) writeSpace() {
+	if b := s.sb.Bytes(); len(b) == 0 || b[len(b)-1] != ' ' {
+		s.sb.WriteByte(' ')
+	}
+}
+
+// handleDirective calls the handler of a directive or an annotation if text of a single line comment matches it,
+// and reports whether it is handled.
+func (s *Separator) handleDirective(text string) bool {
+	for _, d := range s.directives {
+		if strings.HasPrefix(text, d.prefix) {
+			d.fn(text)
+			return true
+		}
+	}
+	if len(s.annotations) == 0 || !strings.HasPrefix(text, "--") {
+		return false
+	}
+	body := strings.TrimLeft(text[len("--"):], " \t")
+	for _, a := range s.annotations {
+		if strings.HasPrefix(body, a.prefix) {
+			fields := strings.TrimSpace(body[len(a.prefix):])
+			name, rest := fields, ""
+			if i := strings.IndexAny(fields, " \t"); i >= 0 {
+				name, rest = fields[:i], strings.TrimSpace(fields[i:])
+			}
+			a.fn(name, rest)
+			return true
+		}
+	}
+	return false
+}
+
+// commentPrefix returns the longest comment prefix at the beginning of the remaining input and its terminator.
+// As it is called at each position, the first `--` of a run of dashes starts a comment.
+// prefix is empty if the remaining input doesn't start with a comment. block reports whether it is a multiline comment.
+func (s *Separator) commentPrefix() (prefix, terminate string, block bool) {
+	if s.hashComments && strings.HasPrefix(s.str, "#") {
+		// single line comment "#"
+		prefix, terminate = "#", "\n"
+	}
+	if strings.HasPrefix(s.str, "--") && (!s.dashCommentRequireSpace || s.followedBySpace(len("--"))) {
+		// single line comment "--"
+		prefix, terminate = "--", "\n"
+	}
+	for _, p := range s.lineCommentPrefixes {
+		if len(p) > len(prefix) && strings.HasPrefix(s.str, p) {
+			prefix, terminate = p, "\n"
+		}
+	}
+	if open := s.blockCommentOpen; strings.HasPrefix(s.str, open) && len(prefix) < len(open) {
+		// multi line comments "/* */", or configured by WithBlockCommentDelimiters
+		// NOTE: Nested multiline comments are not supported in Spanner, but they can be enabled by WithNestedComments.
+		// https://cloud.google.com/spanner/docs/lexical#multiline_comments
+		return open, s.blockCommentClose, true
+	}
+	return prefix, terminate, false
+}
+
+// followedBySpace reports whether the remaining input after n bytes starts with a whitespace or ends.
+func (s *Separator) followedBySpace(n int) bool {
+	r, size := utf8.DecodeRuneInString(s.str[n:])
+	return size == 0 || unicode.IsSpace(r)
+}
+
+// Next returns the next statement in input.
+// ok is false when input is exhausted.
+// This does not validate syntax of statements.
+//
+// NOTE: Logic for parsing a statement is mostly taken from spansql.
+// https://github.com/googleapis/google-cloud-go/blob/master/spanner/spansql/parser.go
+func (s *Separator) Next() (stmt InputStatement, ok bool) {
+	for {
+		stmt, ok = s.next()
+		if !ok || !s.skipEmpty || !s.empty {
+			break
+		}
+	}
+	if !ok {
+		return stmt, ok
+	}
+	if s.count++; s.maxStatements > 0 && s.count > s.maxStatements {
+		s.err = &TooManyStatementsError{Max: s.maxStatements, Count: s.count}
+		return InputStatement{}, false
+	}
+	stmt.Index = s.count - 1
+	if s.transform != nil {
+		stmt = s.transform(stmt)
+	}
+	return stmt, ok
+}
+
+// next returns the next statement in input, including empty statements.
+func (s *Separator) next() (stmt InputStatement, ok bool) {
+	if s.err != nil {
+		return InputStatement{}, false
+	}
+	if s.headerPending {
+		s.headerPending = false
+		s.consumeHeader()
+	}
+	for len(s.str) > 0 {
+		if s.ctx != nil && s.steps%contextCheckInterval == 0 {
+			if err := s.ctx.Err(); err != nil {
+				s.err = err
+				return InputStatement{}, false
+			}
+		}
+		s.steps++
+		if s.invalidUTF8 >= 0 && s.offset() > s.invalidUTF8 {
+			s.err = &InvalidUTF8Error{Offset: s.base + s.invalidUTF8}
+			return InputStatement{}, false
+		}
+
+		pos, n := s.offset(), s.sb.Len()
+		s.skipComments()
+		if s.preserveComments {
+			// replacements of stripped comments are not a part of input.
+			s.track(pos, n)
+		}
+		if s.exceedsLength(pos, n) {
+			return InputStatement{}, false
+		}
+		if len(s.str) == 0 {
+			break
+		}
+
+		pos, n = s.offset(), s.sb.Len()
+		if s.delimiterCommand && !s.hasToken && s.consumeDelimiterCommand() {
+			s.emitToken(TokenOther, pos)
+			continue
+		}
+		if s.batchSeparator != "" && s.consumeBatchSeparator() {
+			return s.flush(strings.ToUpper(s.batchSeparator), pos), true
+		}
+		if s.blankLineSeparator && s.hasToken && s.depth == 0 && s.blockDepth == 0 && s.consumeBlankLine() {
+			s.emitToken(TokenOther, pos)
+			return s.flush("", pos), true
+		}
+		if s.delimiter != "" && s.depth == 0 && s.blockDepth == 0 && strings.HasPrefix(s.str, s.delimiter) {
+			s.str = s.str[len(s.delimiter):]
+			return s.flush(s.delimiter, pos), true
+		}
+
+		if s.blockBegin != "" && s.blockEnd != "" {
+			s.trackBlock()
+		}
+
+		kind := TokenOther
+		typed := false
+		if s.typedLiterals {
+			if n := s.typedLiteralPrefix(); n > 0 {
+				// the following string is consumed below.
+				s.sb.WriteString(s.str[:n])
+				s.str = s.str[n:]
+				typed = true
+			}
+		}
+		switch s.str[0] {
+		// possibly string literal
+		case '"', '\'', 'r', 'R', 'b', 'B':
+			// valid string prefix: "b", "B", "r", "R", "br", "bR", "Br", "BR"
+			// https://cloud.google.com/spanner/docs/lexical#string_and_bytes_literals
+			raw, bytes, str := false, false, false
+			for i := 0; i < 3 && i < len(s.str); i++ {
+				switch {
+				case !raw && s.rawStrings && (s.str[i] == 'r' || s.str[i] == 'R'):
+					raw = true
+					continue
+				case !bytes && s.bytesStrings && (s.str[i] == 'b' || s.str[i] == 'B'):
+					bytes = true
+					continue
+				case s.str[i] == '"' || s.str[i] == '\'':
+					str = true
+					switch {
+					case raw && bytes:
+						s.consumeRawBytesString()
+						kind = TokenBytesString
+					case raw:
+						s.consumeRawString()
+						kind = TokenRawString
+					case bytes:
+						s.consumeBytesString()
+						kind = TokenBytesString
+					default:
+						s.consumeString()
+						kind = TokenString
+					}
+				}
+				break
+			}
+			if !str {
+				// not a string prefix, but possibly a custom terminator like `bye`
+				if term, ok := s.consumeTerminator(); ok {
+					return s.flush(term, pos), true
+				}
+				s.sb.WriteByte(s.str[0])
+				s.str = s.str[1:]
+			}
+		// possibly escape string
+		case 'e', 'E':
+			if s.escapeString && s.hasEscapeStringPrefix() {
+				s.sb.WriteByte(s.str[0])
+				s.str = s.str[1:]
+				s.consumeString()
+				kind = TokenString
+				break
+			}
+			if term, ok := s.consumeTerminator(); ok {
+				return s.flush(term, pos), true
+			}
+			s.consumeRune()
+		// quoted identifier
+		case '`':
+			s.consumeQuotedIdentifier()
+			kind = TokenIdentifier
+		// possibly dollar-quoted string
+		case '$':
+			if s.dollarQuoting {
+				if delim := dollarQuoteDelimiter(s.str); delim != "" {
+					s.consumeDollarQuotedString(delim)
+					kind = TokenRawString
+					break
+				}
+			}
+			if term, ok := s.consumeTerminator(); ok {
+				return s.flush(term, pos), true
+			}
+			s.sb.WriteByte(s.str[0])
+			s.str = s.str[1:]
+		// possibly line continuation
+		case '\\':
+			if term, ok := s.consumeTerminator(); ok {
+				return s.flush(term, pos), true
+			}
+			if s.warnBackslash != nil && len(s.str) > 1 && isASCIILetter(s.str[1]) {
+				s.warnBackslash(s.str[:2], s.base+pos)
+			}
+			if s.lineContinuation {
+				if n := lineContinuationLength(s.str); n > 0 {
+					s.str = s.str[n:]
+					break
+				}
+			}
+			s.sb.WriteByte(s.str[0])
+			s.str = s.str[1:]
+		// possibly placeholder or hint
+		case '@', '?':
+			if term, ok := s.consumeTerminator(); ok {
+				return s.flush(term, pos), true
+			}
+			if s.hintAwareness && strings.HasPrefix(s.str, "@{") {
+				s.consumeHint()
+				break
+			}
+			if s.collectPlaceholders {
+				s.consumePlaceholder()
+				break
+			}
+			s.sb.WriteByte(s.str[0])
+			s.str = s.str[1:]
+		default:
+			if term, ok := s.consumeTerminator(); ok {
+				return s.flush(term, pos), true
+			}
+			if s.bracketAware || s.parenAware {
+				s.trackDepth()
+			}
+			if s.collapseSpace && isSpace(s.str[0]) {
+				s.writeSpace()
+				s.str = s.str[1:]
+				break
+			}
+			s.consumeRune()
+		}
+		if typed {
+			kind = TokenTypedLiteral
+		}
+		s.emitToken(kind, pos)
+		if s.currentDelimiter != "" {
+			s.openOffset = pos
+		}
+		if (!s.hasToken || len(s.trailing) > 0) && len(bytes.TrimSpace(s.sb.Bytes()[n:])) > 0 {
+			s.hasToken = true
+			// comments followed by a token are not trailing comments.
+			s.trailing = nil
+		}
+		s.track(pos, n)
+		if s.err != nil || s.exceedsLength(pos, n) {
+			return InputStatement{}, false
+		}
+	}
+
+	if s.invalidUTF8 >= 0 {
+		s.err = &InvalidUTF8Error{Offset: s.base + s.invalidUTF8}
+		return InputStatement{}, false
+	}
+
+	if s.strict && s.currentDelimiter != "" {
+		err := &UnclosedError{Offset: s.base + s.openOffset, Delimiter: s.currentDelimiter, Kind: s.waitingKind()}
+		if err.Kind == WaitingStringLiteral {
+			err.CommentMarker = s.commentMarker(s.input[s.openOffset:])
+		}
+		s.err = err
+		return InputStatement{}, false
+	}
+
+	// flush remained
+	if s.sb.Len() > 0 || len(s.leading) > 0 {
+		if s.dropTrailingCommentOnly && !s.hasToken {
+			s.sb.Reset()
+			s.leading = nil
+			return InputStatement{}, false
+		}
+		if len(s.trim(s.sb.Bytes())) > 0 || !s.trimSpace || len(s.leading) > 0 {
+			stmt := s.flush("", s.offset())
+			stmt.TerminatorOffset, stmt.Terminated = -1, false
+			return stmt, true
+		}
+		s.sb.Reset()
+	}
+	return InputStatement{}, false
+}
+
+// Err returns the first error encountered by the Separator.
+// It should be checked after Next returns false.
+func (s *Separator) Err() error {
+	return s.err
+}
+
+// Status returns the current status of the Separator.
+// It is useful to know what is waited for, after Next returns false.
+func (s *Separator) Status() Status {
+	return Status{
+		WaitingString: s.currentDelimiter,
+		WaitingKind:   s.waitingKind(),
+	}
+}
+
+// waitingKind returns the kind of the token which waits for currentDelimiter.
+func (s *Separator) waitingKind() WaitingKind {
+	if s.currentDelimiter != "" && s.currentDelimiter == s.blockCommentClose {
+		return WaitingComment
+	}
+	return waitingKind(s.currentDelimiter)
+}
+
+// ReadAll returns all remaining statements and the first error encountered by the Separator.
+func (s *Separator) ReadAll() ([]InputStatement, error) {
+	var statements []InputStatement
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			return statements, s.Err()
+		}
+		statements = append(statements, stmt)
+	}
+}
+
+// consumeDelimiterCommand consumes a MySQL-style `DELIMITER <token>` line and changes the delimiter to the token.
+// It reports whether the remaining input starts with the command.
+func (s *Separator) consumeDelimiterCommand() bool {
+	const command = "DELIMITER"
+	if len(s.str) <= len(command) || !strings.EqualFold(s.str[:len(command)], command) ||
+		(s.str[len(command)] != ' ' && s.str[len(command)] != '\t') {
+		return false
+	}
+
+	line := s.str
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i+1]
+	}
+	delim := strings.TrimSpace(line[len(command):])
+	if delim == "" {
+		return false
+	}
+	s.delimiter = delim
+	s.str = s.str[len(line):]
+	return true
+}
+
+// consumeBatchSeparator consumes the batch separator if it is the only token in the current line.
+// It reports whether the batch separator is consumed.
+func (s *Separator) consumeBatchSeparator() bool {
+	word := s.batchSeparator
+	if len(s.str) < len(word) || !strings.EqualFold(s.str[:len(word)], word) {
+		return false
+	}
+
+	// only whitespaces are allowed before the word
+	before := strings.TrimRight(s.input[s.inputStart:s.offset()], " \t")
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		return false
+	}
+
+	// only whitespaces are allowed after the word
+	after := strings.TrimLeft(s.str[len(word):], " \t\r")
+	if after != "" && after[0] != '\n' {
+		return false
+	}
+
+	s.str = s.str[len(word):]
+	return true
+}
+
+// consumeBlankLine consumes whitespaces up to the end of the first blank line if the remaining input starts with it,
+// including the end of the current line. It reports whether a blank line is consumed.
+func (s *Separator) consumeBlankLine() bool {
+	if !isSpace(s.str[0]) {
+		return false
+	}
+	i := 0
+	if pos := s.offset(); pos > 0 && s.input[pos-1] != '\n' {
+		// not at the beginning of a line, so the current line must be ended first.
+		if s.str[0] != '\n' {
+			return false
+		}
+		i = 1
+	}
+	for i < len(s.str) && (s.str[i] == ' ' || s.str[i] == '\t' || s.str[i] == '\r') {
+		i++
+	}
+	if i >= len(s.str) || s.str[i] != '\n' {
+		return false
+	}
+	s.str = s.str[i+1:]
+	return true
+}
+
+// consumeTerminator consumes a custom terminator if the remaining input starts with it.
+func (s *Separator) consumeTerminator() (string, bool) {
+	if s.depth > 0 || s.blockDepth > 0 {
+		// in brackets or blocks
+		return "", false
+	}
+	// TODO: may need some optimization
+	for _, term := range s.terms {
+		if s.caseInsensitiveTerms {
+			if n, ok := s.hasWordPrefixFold(term); ok {
+				s.str = s.str[n:]
+				return term, true
+			}
+			continue
+		}
+		if strings.HasPrefix(s.str, term) && (!s.wordBoundaryTerms || s.atWordBoundary(term, len(term))) {
+			s.str = s.str[len(term):]
+			return term, true
+		}
+	}
+	if s.termRegexp != nil {
+		// zero-length matches are ignored to avoid infinite loops.
+		if loc := s.termRegexp.FindStringIndex(s.str); loc != nil && loc[1] > 0 {
+			term := s.str[:loc[1]]
+			s.str = s.str[loc[1]:]
+			return term, true
+		}
+	}
+	return "", false
+}
+
+// hasEscapeStringPrefix reports whether the remaining input starts with the prefix of an escape string like `E'`.
+func (s *Separator) hasEscapeStringPrefix() bool {
+	if len(s.str) < 2 || (s.str[1] != '\'' && s.str[1] != '"') {
+		return false
+	}
+	// the prefix must not be the last character of an identifier like `name'...'`
+	prev, size := utf8.DecodeLastRuneInString(s.input[:s.offset()])
+	return size == 0 || !isWordRune(prev)
+}
+
+// commentMarker returns the first comment marker recognized by the Separator in text, or "" if none.
+func (s *Separator) commentMarker(text string) string {
+	markers := append([]string{"--", s.blockCommentOpen}, s.lineCommentPrefixes...)
+	if s.hashComments {
+		markers = append(markers, "#")
+	}
+	first, marker := len(text), ""
+	for _, m := range markers {
+		if i := strings.Index(text, m); m != "" && i >= 0 && i < first {
+			first, marker = i, m
+		}
+	}
+	return marker
+}
+
+// consumeHint consumes a hint `@{...}` with balanced braces.
+func (s *Separator) consumeHint() {
+	// consume '@'
+	s.sb.WriteByte(s.str[0])
+	s.str = s.str[1:]
+
+	depth := 0
+	for len(s.str) > 0 {
+		switch s.str[0] {
+		case '\'', '"':
+			s.consumeString()
+			continue
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		s.consumeRune()
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// trackDepth updates the nesting level of brackets by the next character.
+func (s *Separator) trackDepth() {
+	switch c := s.str[0]; {
+	case c == '(' || c == '[' && s.bracketAware:
+		s.depth++
+	case (c == ')' || c == ']' && s.bracketAware) && s.depth > 0:
+		s.depth--
+	}
+}
+
+// trackBlock updates blockDepth if the remaining input starts with blockBegin or blockEnd,
+// or `CASE` if blockEnd is `END`. Keywords themselves are left to be consumed as ordinary characters.
+func (s *Separator) trackBlock() {
+	endKeyword := strings.EqualFold(s.blockEnd, "END")
+	if _, ok := s.hasWordPrefixFold(s.blockBegin); ok {
+		s.blockDepth++
+	} else if _, ok := s.hasWordPrefixFold("CASE"); ok && endKeyword && s.offset() != s.endCase {
+		s.blockDepth++
+	} else if n, ok := s.hasWordPrefixFold(s.blockEnd); ok && s.blockDepth > 0 {
+		if !endKeyword {
+			s.blockDepth--
+			return
+		}
+		switch word, rest := nextKeyword(s.str[n:]); word {
+		case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+			// `END IF` and so on close statements whose keywords are not counted.
+		case "CASE":
+			s.blockDepth--
+			s.endCase = s.offset() + len(s.str) - len(rest) - len(word)
+		default:
+			s.blockDepth--
+		}
+	}
+}
+
+// isASCIILetter reports whether c is an ASCII letter.
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// lineContinuationLength returns the byte length of a backslash and a newline at the beginning of str, or 0 if none.
+func lineContinuationLength(str string) int {
+	switch {
+	case strings.HasPrefix(str, "\\\n"):
+		return 2
+	case strings.HasPrefix(str, "\\\r\n"):
+		return 3
+	default:
+		return 0
+	}
+}
+
+// consumePlaceholder consumes `@`, `@@`, or `?` with the following name, and collects it if it is a placeholder.
+func (s *Separator) consumePlaceholder() {
+	pos := s.offset()
+	n := 1
+	if strings.HasPrefix(s.str, "@@") {
+		// system variable
+		n = 2
+	}
+	if n == 1 && strings.HasPrefix(s.str, "@`") {
+		// quoted parameter name. Only `@` is consumed here, so that the name is consumed later as a quoted identifier.
+		if ident, _, ok := ConsumeQuotedIdentifier(s.str[1:]); ok {
+			s.placeholders = append(s.placeholders, Placeholder{Text: s.str[:n+len(ident)], Offset: s.base + pos})
+		}
+		s.sb.WriteByte(s.str[0])
+		s.str = s.str[1:]
+		return
+	}
+	if s.str[0] == '@' {
+		if i := strings.IndexFunc(s.str[n:], func(r rune) bool { return !isWordRune(r) }); i >= 0 {
+			n += i
+		} else {
+			n = len(s.str)
+		}
+	}
+	text := s.str[:n]
+	s.sb.WriteString(text)
+	s.str = s.str[n:]
+	if text == "?" || (len(text) > 1 && text[1] != '@') {
+		s.placeholders = append(s.placeholders, Placeholder{Text: text, Offset: s.base + pos})
+	}
+}
+
+// typedLiteralKeywords is keywords of typed literals recognized by WithTypedLiterals.
+var typedLiteralKeywords = []string{"DATE", "DATETIME", "TIME", "TIMESTAMP", "NUMERIC", "BIGNUMERIC", "JSON", "INTERVAL"}
+
+// typedLiteralPrefix returns the byte length of the keyword and whitespaces of a typed literal
+// at the beginning of the remaining input, or 0 if there is no typed literal.
+func (s *Separator) typedLiteralPrefix() int {
+	if prev, size := utf8.DecodeLastRuneInString(s.input[:s.offset()]); size > 0 && isWordRune(prev) {
+		return 0
+	}
+	for _, keyword := range typedLiteralKeywords {
+		if len(s.str) <= len(keyword) || !strings.EqualFold(s.str[:len(keyword)], keyword) {
+			continue
+		}
+		n := len(keyword)
+		for n < len(s.str) && isSpace(s.str[n]) {
+			n++
+		}
+		if n < len(s.str) && (s.str[n] == '\'' || s.str[n] == '"') {
+			return n
+		}
+	}
+	return 0
+}
+
+// hasWordPrefixFold reports whether the remaining input starts with term case-insensitively at word boundaries.
+// n is the byte length of the matched text, which can differ from len(term).
+func (s *Separator) hasWordPrefixFold(term string) (n int, ok bool) {
+	n, ok = hasPrefixFold(s.str, term)
+	if !ok || n == 0 {
+		return n, ok
+	}
+	return n, s.atWordBoundary(term, n)
+}
+
+// atWordBoundary reports whether term, matched as the first n bytes of the remaining input, is not a part of a word.
+// Boundaries are checked only at ends of term which are word characters.
+func (s *Separator) atWordBoundary(term string, n int) bool {
+	if first, _ := utf8.DecodeRuneInString(term); isWordRune(first) {
+		if prev, size := utf8.DecodeLastRuneInString(s.input[:s.offset()]); size > 0 && isWordRune(prev) {
+			return false
+		}
+	}
+	if last, _ := utf8.DecodeLastRuneInString(term); isWordRune(last) {
+		if next, size := utf8.DecodeRuneInString(s.str[n:]); size > 0 && isWordRune(next) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPrefixFold reports whether s starts with prefix under simple Unicode case folding, comparing rune by rune.
+// n is the byte length of the matched text in s.
+func hasPrefixFold(s, prefix string) (n int, ok bool) {
+	for _, pr := range prefix {
+		if n >= len(s) {
+			return 0, false
+		}
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !equalFoldRune(r, pr) {
+			return 0, false
+		}
+		n += size
+	}
+	return n, true
+}
+
+// equalFoldRune reports whether r1 and r2 are equal under simple Unicode case folding.
+func equalFoldRune(r1, r2 rune) bool {
+	if r1 == r2 {
+		return true
+	}
+	for f := unicode.SimpleFold(r1); f != r1; f = unicode.SimpleFold(f) {
+		if f == r2 {
+			return true
+		}
+	}
+	return false
+}
+
+// isWordRune reports whether r can be a part of an unquoted identifier or a keyword.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// flush returns the accumulated statement terminated by terminator at pos and resets the buffer.
+func (s *Separator) flush(terminator string, pos int) InputStatement {
+	if terminator != "" {
+		s.emitToken(TokenTerminator, pos)
+	}
+	trailing := s.trailing
+	if len(trailing) > 0 {
+		s.sb.Truncate(s.trailingAt)
+		s.end = s.trailingEnd
+	}
+	b := s.sb.Bytes()
+	space := b[len(bytes.TrimRightFunc(b, s.isTrimmed)):]
+	keepRight := s.terminatorSpace && terminator != ""
+	switch {
+	case s.trimSpace && s.commentFirst:
+		// keep the indent of the leading comment in its line.
+		left := bytes.TrimLeftFunc(b, s.isTrimmed)
+		b = b[bytes.LastIndexByte(b[:len(b)-len(left)], '\n')+1:]
+		if !keepRight {
+			b = bytes.TrimRightFunc(b, s.isTrimmed)
+		}
+	case s.trimSpace && keepRight:
+		b = bytes.TrimLeftFunc(b, s.isTrimmed)
+	case s.trimSpace:
+		b = s.trim(b)
+	}
+	stmt := InputStatement{
+		Terminator: terminator,
+		Start:      pos,
+		End:        pos,
+	}
+	if canonical, ok := s.canonicalTerms[terminator]; ok {
+		stmt.Terminator = canonical
+	}
+	if !s.discard {
+		stmt.Statement = string(b)
+	}
+	s.empty = len(bytes.TrimFunc(b, s.isTrimmed)) == 0
+	if s.keepTerminator && !s.discard {
+		if first, _ := utf8.DecodeRuneInString(terminator); isWordRune(first) && !s.empty && !bytes.HasSuffix(b, space) {
+			// keep the whitespaces before a word-like terminator like `GO` so that it doesn't fuse with the statement.
+			switch {
+			case s.batchSeparator != "" && terminator == strings.ToUpper(s.batchSeparator) && bytes.IndexByte(space, '\n') < 0:
+				// a batch separator must be on its own line, even if the line break was a part of a stripped comment.
+				space = []byte("\n")
+			case len(space) == 0:
+				space = []byte(" ")
+			}
+			stmt.Statement += string(space)
+		}
+		stmt.Statement += terminator
+	}
+	if s.start >= 0 {
+		stmt.Start, stmt.End = s.start, s.end
+	}
+	stmt.Line, stmt.Column = s.position(stmt.Start)
+	stmt.Start += s.base
+	stmt.End += s.base
+	stmt.TerminatorOffset = s.base + pos
+	stmt.Terminated = true
+	stmt.LeadingComments, stmt.TrailingComments = s.leading, trailing
+	stmt.Placeholders = s.placeholders
+	if s.headerStmt {
+		stmt.Header, s.headerStmt = s.header, false
+	}
+	if s.source {
+		stmt.Source = s.input[s.sourceStart:s.offset()]
+	}
+	s.sourceStart = s.offset()
+	s.sb.Reset()
+	s.start = -1
+	s.hasToken = false
+	s.runes = 0
+	s.leading, s.trailing = nil, nil
+	s.placeholders = nil
+	s.depth, s.blockDepth = 0, 0
+	s.commentFirst = false
+	return stmt
+}
+
+// emitToken passes the token from pos to the current offset to tokenFn if it is set.
+func (s *Separator) emitToken(kind TokenKind, pos int) {
+	if s.tokenFn != nil {
+		s.tokenFn(Token{Kind: kind, Text: s.input[pos:s.offset()], Start: s.base + pos, End: s.base + s.offset()})
+	}
+}
+
+// exceedsLength reports whether the current statement exceeds maxStatementLength by text written to the buffer after n,
+// and sets the error if so. pos is the offset of the written text in input.
+func (s *Separator) exceedsLength(pos, n int) bool {
+	if s.maxStatementLength <= 0 {
+		return false
+	}
+	written := s.sb.Bytes()[n:]
+	if s.runes == 0 {
+		// leading whitespaces are not counted
+		written = bytes.TrimLeftFunc(written, unicode.IsSpace)
+	}
+	s.runes += utf8.RuneCount(written)
+	if s.runes <= s.maxStatementLength {
+		return false
+	}
+	if s.start >= 0 {
+		pos = s.start
+	}
+	s.err = &StatementTooLongError{Offset: s.base + pos, Max: s.maxStatementLength}
+	return true
+}
+
+// consumeRune consumes a rune as an ordinary character.
+func (s *Separator) consumeRune() {
+	size := 1
+	if s.str[0] >= utf8.RuneSelf {
+		_, size = utf8.DecodeRuneInString(s.str)
+	}
+	s.sb.WriteString(s.str[:size])
+	s.str = s.str[size:]
+}
+
+// offset returns the byte offset of the remaining input in the original input.
+func (s *Separator) offset() int {
+	return len(s.input) - len(s.str)
+}
+
+// position returns 1-based line and column numbers of the byte offset in the original input.
+// offset must not be less than offset of the previous call.
+func (s *Separator) position(offset int) (line, column int) {
+	for s.lines.offset < offset {
+		r, size := utf8.DecodeRuneInString(s.input[s.lines.offset:])
+		s.lines.offset += size
+		if r == '\n' {
+			s.lines.line++
+			s.lines.column = 1
+		} else {
+			s.lines.column++
+		}
+	}
+	return s.lines.line, s.lines.column
+}
+
+// rebase makes positions of statements relative to the larger input, in which input starts at offset, line, and column.
+func (s *Separator) rebase(offset, line, column int) {
+	s.base = offset
+	s.lines.line, s.lines.column = line, column
+	if offset > 0 {
+		// input is not the beginning of the larger input, so a BOM must not be skipped.
+		s.keepBOM()
+	}
+}
+
+// keepBOM restores a UTF-8 BOM skipped at the beginning of input by Reset,
+// because input is not the beginning of the larger input.
+func (s *Separator) keepBOM() {
+	s.str = s.input
+	s.lines.offset = 0
+	s.inputStart = 0
+	s.sourceStart = 0
+	s.headerPending = false
+}
+
+// track updates the span of the current statement by text written to the buffer after n.
+// The written text must be the verbatim copy of input from pos, or blank.
+func (s *Separator) track(pos, n int) {
+	written := s.sb.Bytes()[n:]
+	if len(written) == 1 && written[0] < utf8.RuneSelf && !s.isTrimmed(rune(written[0])) {
+		// fast path for an ordinary ASCII character
+		if s.start < 0 {
+			s.start = pos
+		}
+		s.end = pos + 1
+		return
+	}
+	trimmed := bytes.TrimLeftFunc(written, s.isTrimmed)
+	if len(trimmed) == 0 {
+		return
+	}
+	if s.start < 0 {
+		s.start = pos + len(written) - len(trimmed)
+	}
+	s.end = s.offset() - (len(trimmed) - len(bytes.TrimRightFunc(trimmed, s.isTrimmed)))
+}
+
+// isTrimmed reports whether r is trimmed around statements.
+func (s *Separator) isTrimmed(r rune) bool {
+	if s.trimFunc != nil {
+		return s.trimFunc(r)
+	}
+	if r < utf8.RuneSelf {
+		return isSpace(byte(r))
+	}
+	return unicode.IsSpace(r)
+}
+
+// trim returns b without leading and trailing characters trimmed around statements.
+func (s *Separator) trim(b []byte) []byte {
+	if s.trimFunc == nil {
+		return bytes.TrimSpace(b)
+	}
+	return bytes.TrimFunc(b, s.trimFunc)
+}
+
+// separate separates input string into multiple Spanner statements.
+func (s *Separator) separate() ([]InputStatement, Status) {
+	// Estimate the number of statements by terminators to reduce allocations.
+	// It can be overestimated because terminators can appear in strings and comments.
+	estimate := 1
+	if s.delimiter != "" {
+		estimate += strings.Count(s.str, s.delimiter)
+	}
+	for _, term := range s.terms {
+		if term != "" {
+			estimate += strings.Count(s.str, term)
+		}
+	}
+	s.sb.Grow(len(s.str) / estimate)
+
+	var statements []InputStatement
+	for {
+		stmt, ok := s.Next()
+		if !ok {
+			break
+		}
+		if statements == nil {
+			statements = make([]InputStatement, 0, estimate)
+		}
+		statements = append(statements, stmt)
+	}
+	return statements, s.Status()
+}
+
+// isSpace reports whether c is an ASCII whitespace, which is also unicode.IsSpace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r'
+}
+
+// dollarQuoteDelimiter returns the delimiter `$tag$` if s starts with a dollar-quoted string, otherwise "".
+// The tag follows the rule of PostgreSQL, which is the same as unquoted identifiers but can't contain `$`.
+// https://www.postgresql.org/docs/current/sql-syntax-lexical.html#SQL-SYNTAX-DOLLAR-QUOTING
+func dollarQuoteDelimiter(s string) string {
+	for i := 1; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == '$' {
+			return s[:i+1]
+		}
+		if c != '_' && !unicode.IsLetter(c) && (i == 1 || !unicode.IsDigit(c)) {
+			return ""
+		}
+		i += size
+	}
+	return ""
 }
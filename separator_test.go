@@ -16,11 +16,33 @@
 package gsqlsep
 
 import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
+// ignorePositions ignores position fields, Terminated, and Index of InputStatement, which are tested separately.
+var ignorePositions = cmpopts.IgnoreFields(InputStatement{}, "Start", "End", "Line", "Column", "TerminatorOffset", "Terminated", "Index")
+
+// ignorePositionsKeepTerminated is the same as ignorePositions, but Terminated is compared.
+var ignorePositionsKeepTerminated = cmpopts.IgnoreFields(InputStatement{}, "Start", "End", "Line", "Column", "TerminatorOffset", "Index")
+
+// statements returns Statement of each stmts.
+func statements(stmts []InputStatement) []string {
+	var result []string
+	for _, stmt := range stmts {
+		result = append(result, stmt.Statement)
+	}
+	return result
+}
+
 func TestSeparatorSkipComments(t *testing.T) {
 	for _, tt := range []struct {
 		desc         string
@@ -145,6 +167,48 @@ func TestSeparatorConsumeString(t *testing.T) {
 			want:         `"テスト"`,
 			wantRemained: " WHERE",
 		},
+		{
+			desc:         "empty triple-quoted string",
+			str:          `"""""" WHERE`,
+			want:         `""""""`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "triple-quoted string with a quote",
+			str:          `"""a"b""" WHERE`,
+			want:         `"""a"b"""`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "triple-quoted string with two consecutive quotes",
+			str:          `'''a''b''' WHERE`,
+			want:         `'''a''b'''`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "triple-quoted string with two consecutive quotes at the beginning",
+			str:          `"""""a""" WHERE`,
+			want:         `"""""a"""`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "triple-quoted string closed by the first triple quote in a run",
+			str:          `"""a""""" WHERE`,
+			want:         `"""a"""`,
+			wantRemained: `"" WHERE`,
+		},
+		{
+			desc:         "triple-quoted string with an escaped quote before closing",
+			str:          `"""a\"""" WHERE`,
+			want:         `"""a\""""`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "nine quotes",
+			str:          `"""""""""`,
+			want:         `""""""`,
+			wantRemained: `"""`,
+		},
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
 			s := newSeparator(tt.str, false, nil)
@@ -268,6 +332,84 @@ func TestSeparatorConsumeRawBytesString(t *testing.T) {
 			want:         `RB"test"`,
 			wantRemained: " WHERE",
 		},
+		{
+			desc:         "raw bytes string (rB)",
+			str:          `rB'test' WHERE`,
+			want:         `rB'test'`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "raw bytes string (Rb)",
+			str:          `Rb'test' WHERE`,
+			want:         `Rb'test'`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "raw bytes string (br)",
+			str:          `br'test' WHERE`,
+			want:         `br'test'`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "raw bytes string (bR)",
+			str:          `bR'test' WHERE`,
+			want:         `bR'test'`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "raw bytes string (Br)",
+			str:          `Br'test' WHERE`,
+			want:         `Br'test'`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "raw bytes string (BR)",
+			str:          `BR'''te\'st''' WHERE`,
+			want:         `BR'''te\'st'''`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "triple-quoted raw bytes string with newline",
+			str:          "rb\"\"\"a\nb\"\"\"; SELECT 1",
+			want:         "rb\"\"\"a\nb\"\"\"",
+			wantRemained: "; SELECT 1",
+		},
+		{
+			desc:         "triple-quoted raw bytes string (RB)",
+			str:          `RB'''x''' WHERE`,
+			want:         `RB'''x'''`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "triple-quoted raw bytes string with quotes and backslashes",
+			str:          `rb"""a"b""c\""" WHERE`,
+			want:         `rb"""a"b""c\"""`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "empty triple-quoted raw bytes string",
+			str:          `bR'''''' WHERE`,
+			want:         `bR''''''`,
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "prefix at the end of input",
+			str:          "rb",
+			want:         "rb",
+			wantRemained: "",
+		},
+		{
+			desc:         "prefix followed by non-quote",
+			str:          "Rb_col 'a'",
+			want:         "Rb",
+			wantRemained: "_col 'a'",
+		},
+		{
+			desc:         "single rune",
+			str:          "r",
+			want:         "r",
+			wantRemained: "",
+		},
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
 			s := newSeparator(tt.str, false, nil)
@@ -286,6 +428,75 @@ func TestSeparatorConsumeRawBytesString(t *testing.T) {
 	}
 }
 
+func TestSeparateInput_RawBytesPrefixes(t *testing.T) {
+	for _, prefix := range []string{"rb", "rB", "Rb", "RB", "br", "bR", "Br", "BR"} {
+		for _, quote := range []string{`'`, `"`, `'''`, `"""`} {
+			// a raw string doesn't treat a backslash as an escape character, so the string ends before ';'.
+			literal := prefix + quote + `a;\` + quote
+			input := "SELECT " + literal + "; SELECT 2"
+			want := []string{"SELECT " + literal, "SELECT 2"}
+			if diff := cmp.Diff(want, SeparateInputString(input)); diff != "" {
+				t.Errorf("difference in statements of %q: (-want +got):\n%s", input, diff)
+			}
+		}
+	}
+}
+
+func TestSeparatorConsumeQuotedIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		desc         string
+		str          string
+		want         string
+		wantRemained string
+	}{
+		{
+			desc:         "quoted identifier",
+			str:          "`test` WHERE",
+			want:         "`test`",
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "backslash escaped backtick",
+			str:          "`a\\`b` WHERE",
+			want:         "`a\\`b`",
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "doubled backtick",
+			str:          "`a``b` WHERE",
+			want:         "`a``b`",
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "multiple doubled backticks",
+			str:          "`a````b``` WHERE",
+			want:         "`a````b```",
+			wantRemained: " WHERE",
+		},
+		{
+			desc:         "doubled backtick at the end of input",
+			str:          "`a``",
+			want:         "`a``",
+			wantRemained: "",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := newSeparator(tt.str, false, nil)
+			s.consumeQuotedIdentifier()
+
+			got := s.sb.String()
+			if got != tt.want {
+				t.Errorf("consumeQuotedIdentifier(%q) = %q, but want = %q", tt.str, got, tt.want)
+			}
+
+			remained := string(s.str)
+			if remained != tt.wantRemained {
+				t.Errorf("consumeQuotedIdentifier(%q) remained %q, but want = %q", tt.str, remained, tt.wantRemained)
+			}
+		})
+	}
+}
+
 func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 	const (
 		terminatorHorizontal = `;`
@@ -317,7 +528,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{`"`},
+			wantStatus: Status{WaitingString: `"`, WaitingKind: WaitingStringLiteral},
 		},
 		{
 			desc:  "non-closed single quoted",
@@ -328,7 +539,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{`'`},
+			wantStatus: Status{WaitingString: `'`, WaitingKind: WaitingStringLiteral},
 		},
 		{
 			desc:  "closed single quoted",
@@ -339,7 +550,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{``},
+			wantStatus: Status{},
 		},
 		{
 			desc:  "non-closed back quoted",
@@ -350,7 +561,55 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{"`"},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:  "non-closed back quoted after statements",
+			input: "SELECT 1; SELECT `abc",
+			want: []InputStatement{
+				{
+					Statement:  "SELECT 1",
+					Terminator: terminatorHorizontal,
+				},
+				{
+					Statement:  "SELECT `abc",
+					Terminator: terminatorUndefined,
+				},
+			},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:  "non-closed back quoted with terminator",
+			input: "SELECT `abc;\n",
+			want: []InputStatement{
+				{
+					Statement:  "SELECT `abc;",
+					Terminator: terminatorUndefined,
+				},
+			},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:  "non-closed back quoted with escaped back quote",
+			input: "SELECT `abc\\`",
+			want: []InputStatement{
+				{
+					Statement:  "SELECT `abc\\`",
+					Terminator: terminatorUndefined,
+				},
+			},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:  "non-closed back quoted with doubled back quote",
+			input: "SELECT `abc``",
+			want: []InputStatement{
+				{
+					Statement:  "SELECT `abc``",
+					Terminator: terminatorUndefined,
+				},
+			},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
 		},
 		{
 			desc:  "closed back quoted",
@@ -361,7 +620,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{""},
+			wantStatus: Status{},
 		},
 		{
 			desc:  "closed comment",
@@ -372,7 +631,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{""},
+			wantStatus: Status{},
 		},
 		{
 			desc:  "closed comment",
@@ -383,7 +642,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{"*/"},
+			wantStatus: Status{WaitingString: "*/", WaitingKind: WaitingComment},
 		},
 		{
 			desc:  "non-closed triple double quoted",
@@ -394,7 +653,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{`"""`},
+			wantStatus: Status{WaitingString: `"""`, WaitingKind: WaitingStringLiteral},
 		},
 		{
 			desc:  "closed triple double quoted",
@@ -405,7 +664,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{``},
+			wantStatus: Status{},
 		},
 		{
 			desc:  "non-closed triple single quoted",
@@ -416,7 +675,7 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{`'''`},
+			wantStatus: Status{WaitingString: `'''`, WaitingKind: WaitingStringLiteral},
 		},
 		{
 			desc:  "closed triple single quoted",
@@ -427,12 +686,12 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantStatus: Status{``},
+			wantStatus: Status{},
 		},
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
 			got, gotStatus := SeparateInputPreserveCommentsWithStatus(tt.input)
-			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{})); diff != "" {
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{}), ignorePositions); diff != "" {
 				t.Errorf("difference in statements: (-want +got):\n%s", diff)
 			}
 			if diff := cmp.Diff(tt.wantStatus, gotStatus); diff != "" {
@@ -460,6 +719,7 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT "123"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -470,10 +730,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT "123"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT "456"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -484,10 +746,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  "SELECT `1`, `2`",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  "SELECT `3`, `4`",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -498,6 +762,7 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT "123"`,
 					Terminator: terminatorVertical,
+					Terminated: true,
 				},
 			},
 		},
@@ -508,14 +773,17 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT "123"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT "456"`,
 					Terminator: terminatorVertical,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT "789"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -526,10 +794,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT * FROM t1 WHERE id = "123" AND "456"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `DELETE FROM t2 WHERE true`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -540,10 +810,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT 1`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  ``,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -554,10 +826,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT 1`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT 2`,
 					Terminator: terminatorVertical,
+					Terminated: true,
 				},
 			},
 		},
@@ -568,10 +842,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT "1;2;3"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT 'TL;DR'`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -582,10 +858,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT r"1\G2\G3"`,
 					Terminator: terminatorVertical,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT r'4\G5\G6'`,
 					Terminator: terminatorVertical,
+					Terminated: true,
 				},
 			},
 		},
@@ -596,10 +874,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  "SELECT `1;2`",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  "SELECT `3;4`",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -610,10 +890,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT '123'`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT '456'`,
 					Terminator: terminatorVertical,
+					Terminated: true,
 				},
 			},
 		},
@@ -624,6 +906,7 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  "CREATE t1 (\nId INT64 NOT NULL\n) PRIMARY KEY (Id)",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -634,10 +917,12 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  "SELECT   1",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  "SELECT 2",
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 			},
 		},
@@ -653,6 +938,7 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 				{
 					Statement:  `SELECT "123"`,
 					Terminator: terminatorHorizontal,
+					Terminated: true,
 				},
 				{
 					Statement:  `SELECT "45`,
@@ -673,7 +959,7 @@ func TestSeparateInput_SpannerCliCompatible(t *testing.T) {
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
 			got := SeparateInput(tt.input, `\G`)
-			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{})); diff != "" {
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{}), ignorePositionsKeepTerminated); diff != "" {
 				t.Errorf("difference in statements: (-want +got):\n%s", diff)
 			}
 		})
@@ -854,7 +1140,7 @@ AS r"""
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
 			got := SeparateInputString(tt.input, `\G`)
-			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{})); diff != "" {
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{}), ignorePositions); diff != "" {
 				t.Errorf("difference in statements: (-want +got):\n%s", diff)
 			}
 		})
@@ -1038,7 +1324,7 @@ AS r"""
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
 			got := SeparateInputStringPreserveComments(tt.input, `\G`)
-			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{})); diff != "" {
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(InputStatement{}), ignorePositions); diff != "" {
 				t.Errorf("difference in statements: (-want +got):\n%s", diff)
 			}
 		})
@@ -1117,6 +1403,19 @@ func TestInputStatement_StripComments(t *testing.T) {
 				Terminator: `\G`,
 			},
 		},
+		{
+			desc: "should preserve other fields",
+			input: InputStatement{
+				Statement: "SELECT @a /* comment */", Terminator: ";", Start: 1, End: 24, Line: 2, Column: 3,
+				TerminatorOffset: 24, Terminated: true, LeadingComments: []string{"-- a"}, TrailingComments: []string{"/* comment */"},
+				Placeholders: []Placeholder{{Text: "@a", Offset: 8}}, Index: 4, Source: "-- a\nSELECT @a /* comment */;", Header: "-- header",
+			},
+			want: InputStatement{
+				Statement: "SELECT @a", Terminator: ";", Start: 1, End: 24, Line: 2, Column: 3,
+				TerminatorOffset: 24, Terminated: true, LeadingComments: []string{"-- a"}, TrailingComments: []string{"/* comment */"},
+				Placeholders: []Placeholder{{Text: "@a", Offset: 8}}, Index: 4, Source: "-- a\nSELECT @a /* comment */;", Header: "-- header",
+			},
+		},
 	} {
 		tt := tt
 		t.Run(tt.desc, func(t *testing.T) {
@@ -1127,3 +1426,4272 @@ func TestInputStatement_StripComments(t *testing.T) {
 		})
 	}
 }
+
+func TestStripComments(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			want:  "",
+		},
+		{
+			desc:  "only comments",
+			input: "# comment;\n/* comment */--comment\n/* comment */",
+			want:  "    ",
+		},
+		{
+			desc:  "single line comments",
+			input: "SELECT 1; -- comment\nSELECT 2; # comment\r\nSELECT 3 -- comment",
+			want:  "SELECT 1;  SELECT 2;  SELECT 3 ",
+		},
+		{
+			desc:  "comment as token separator",
+			input: "SELECT 0x1/* comment */A;",
+			want:  "SELECT 0x1 A;",
+		},
+		{
+			desc:  "terminators and whitespaces are preserved",
+			input: "SELECT 1 ;\n\tSELECT 2\\G\n;\n",
+			want:  "SELECT 1 ;\n\tSELECT 2\\G\n;\n",
+		},
+		{
+			desc:  "comment-like sequences in strings and quoted identifiers",
+			input: "SELECT '-- a', \"/* b */\", '''# c\n''', r'--', b'/*', `--d`; -- comment\nSELECT 1",
+			want:  "SELECT '-- a', \"/* b */\", '''# c\n''', r'--', b'/*', `--d`;  SELECT 1",
+		},
+		{
+			desc:  "non-closed multiline comment",
+			input: "SELECT 1; /* comment",
+			want:  "SELECT 1; ",
+		},
+		{
+			desc:  "non-closed string",
+			input: "SELECT 1; SELECT '2 -- a",
+			want:  "SELECT 1; SELECT '2 -- a",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := StripComments(tt.input); got != tt.want {
+				t.Errorf("StripComments(%q) = %q, but want = %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeparator_Next(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []InputStatement
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			want:  nil,
+		},
+		{
+			desc:  "multiple statements",
+			input: "SELECT 1; SELECT 2; SELECT 3",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ";"},
+				{Statement: "SELECT 3", Terminator: ""},
+			},
+		},
+		{
+			desc:  "custom terminators",
+			input: `SELECT 1\G SELECT 2;`,
+			opts:  []Option{WithTerminators(`\G`)},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\G`},
+				{Statement: "SELECT 2", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "preserve comments",
+			input: "SELECT 1 /* comment */; -- comment\nSELECT 2",
+			opts:  []Option{WithPreserveComments(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1 /* comment */", Terminator: ";"},
+				{Statement: "-- comment\nSELECT 2", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := NewSeparator(tt.input, tt.opts...)
+			var got []InputStatement
+			for {
+				stmt, ok := s.Next()
+				if !ok {
+					break
+				}
+				got = append(got, stmt)
+			}
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if _, ok := s.Next(); ok {
+				t.Errorf("Next() after exhausted returns ok = true")
+			}
+		})
+	}
+}
+
+func TestSeparator_NextStopEarly(t *testing.T) {
+	s := NewSeparator("SELECT 1; SELECT 2; SELECT 3;")
+	stmt, ok := s.Next()
+	if !ok {
+		t.Fatalf("Next() returns ok = false")
+	}
+	if diff := cmp.Diff(InputStatement{Statement: "SELECT 1", Terminator: ";"}, stmt, ignorePositions); diff != "" {
+		t.Errorf("difference in statement: (-want +got):\n%s", diff)
+	}
+	if remained, want := string(s.str), " SELECT 2; SELECT 3;"; remained != want {
+		t.Errorf("Next() remained %q, but want = %q", remained, want)
+	}
+}
+
+func TestSeparateInput_Offsets(t *testing.T) {
+	type span struct {
+		Start, End int
+	}
+	for _, tt := range []struct {
+		desc            string
+		input           string
+		preserveComment bool
+		want            []span
+	}{
+		{
+			desc:  "single statement",
+			input: "SELECT 1;",
+			want:  []span{{0, 8}},
+		},
+		{
+			desc:  "multiple statements with whitespaces",
+			input: "  SELECT 1 ;\n\tSELECT 2\n",
+			want:  []span{{2, 10}, {14, 22}},
+		},
+		{
+			desc:  "leading and trailing comments are excluded",
+			input: "/* comment */ SELECT 1 -- comment\n;# comment\nSELECT 2",
+			want:  []span{{14, 22}, {45, 53}},
+		},
+		{
+			desc:  "inner comments are included",
+			input: "SELECT /* comment */ 1;",
+			want:  []span{{0, 22}},
+		},
+		{
+			desc:            "leading and trailing comments are included in preserve mode",
+			input:           "/* comment */ SELECT 1 -- comment\n;",
+			preserveComment: true,
+			want:            []span{{0, 33}},
+		},
+		{
+			desc:  "empty statements",
+			input: "SELECT 1; ;/* comment */;",
+			want:  []span{{0, 8}, {10, 10}, {24, 24}},
+		},
+		{
+			desc:  "custom terminator",
+			input: `SELECT 1\GSELECT 2`,
+			want:  []span{{0, 8}, {10, 18}},
+		},
+		{
+			desc:  "multi-byte characters",
+			input: `SELECT "テスト"; SELECT 'テスト'`,
+			want:  []span{{0, 18}, {20, 38}},
+		},
+		{
+			desc:  "non-closed string",
+			input: "SELECT 1; SELECT '2;",
+			want:  []span{{0, 8}, {10, 20}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			stmts, _ := newSeparator(tt.input, tt.preserveComment, []string{`\G`}).separate()
+			var got []span
+			for _, stmt := range stmts {
+				got = append(got, span{stmt.Start, stmt.End})
+				if tt.preserveComment && tt.input[stmt.Start:stmt.End] != stmt.Statement {
+					t.Errorf("input[%d:%d] = %q, but statement = %q", stmt.Start, stmt.End, tt.input[stmt.Start:stmt.End], stmt.Statement)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in offsets: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateInput_TerminatorOffset(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []int
+	}{
+		{
+			desc:  "semicolons",
+			input: "SELECT 1; SELECT 2 ;",
+			want:  []int{8, 19},
+		},
+		{
+			desc:  "custom terminator",
+			input: `SELECT 1\G SELECT 'テスト'\G`,
+			want:  []int{8, 29},
+		},
+		{
+			desc:  "after trailing comment",
+			input: "SELECT 1 -- comment\n;",
+			want:  []int{20},
+		},
+		{
+			desc:  "empty statement",
+			input: "SELECT 1;;",
+			want:  []int{8, 9},
+		},
+		{
+			desc:  "EOF",
+			input: "SELECT 1; SELECT 2",
+			want:  []int{8, -1},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got []int
+			for _, stmt := range SeparateInput(tt.input, `\G`) {
+				got = append(got, stmt.TerminatorOffset)
+				if stmt.TerminatorOffset >= 0 && !strings.HasPrefix(tt.input[stmt.TerminatorOffset:], stmt.Terminator) {
+					t.Errorf("input[%d:] = %q doesn't start with terminator %q", stmt.TerminatorOffset, tt.input[stmt.TerminatorOffset:], stmt.Terminator)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in terminator offsets: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateInputRanges(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []Range
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			want:  nil,
+		},
+		{
+			desc:  "whitespaces and comments around statements",
+			input: "  SELECT 1 ;\n-- comment\nSELECT 'テスト'\\G/* comment */SELECT 3",
+			want: []Range{
+				{Text: "SELECT 1", Start: 2, End: 10},
+				{Text: "SELECT 'テスト'", Start: 24, End: 42},
+				{Text: "SELECT 3", Start: 57, End: 65},
+			},
+		},
+		{
+			desc:  "inner comments",
+			input: "SELECT /* comment */ 1;",
+			want: []Range{
+				{Text: "SELECT   1", Start: 0, End: 22},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := SeparateInputRanges(tt.input, `\G`)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in ranges: (-want +got):\n%s", diff)
+			}
+			for _, r := range got {
+				// ranges point at the text unless inner comments are stripped
+				if sub := tt.input[r.Start:r.End]; !strings.Contains(sub, "/*") && sub != r.Text {
+					t.Errorf("input[%d:%d] = %q, but text = %q", r.Start, r.End, tt.input[r.Start:r.End], r.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestSeparateInput_LineColumn(t *testing.T) {
+	type position struct {
+		Line, Column int
+	}
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []position
+	}{
+		{
+			desc:  "single line",
+			input: "SELECT 1; SELECT 2;",
+			want:  []position{{1, 1}, {1, 11}},
+		},
+		{
+			desc: "multi-line DDL",
+			input: `CREATE TABLE Singers (
+  SingerId INT64 NOT NULL,
+  Name STRING(MAX),
+) PRIMARY KEY (SingerId);
+
+  CREATE INDEX SingersByName
+  ON Singers (Name);
+`,
+			want: []position{{1, 1}, {6, 3}},
+		},
+		{
+			desc:  "after multi-line comment",
+			input: "SELECT 1;\n/*\n * comment\n */SELECT 2;",
+			want:  []position{{1, 1}, {4, 4}},
+		},
+		{
+			desc:  "after single line comments",
+			input: "-- comment\n# comment\n  SELECT 1;",
+			want:  []position{{3, 3}},
+		},
+		{
+			desc:  "after triple-quoted string",
+			input: "SELECT '''a\nb\nc'''; SELECT 2;",
+			want:  []position{{1, 1}, {3, 7}},
+		},
+		{
+			desc:  "CRLF",
+			input: "SELECT 1;\r\n\r\n  SELECT 2;\r\n/* comment\r\n */ SELECT 3;",
+			want:  []position{{1, 1}, {3, 3}, {5, 5}},
+		},
+		{
+			desc:  "multi-byte characters",
+			input: "SELECT 'テスト'; SELECT 2;",
+			want:  []position{{1, 1}, {1, 15}},
+		},
+		{
+			desc:  "empty statement",
+			input: "SELECT 1;\n  ;",
+			want:  []position{{1, 1}, {2, 3}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got []position
+			for _, stmt := range SeparateInput(tt.input) {
+				got = append(got, position{stmt.Line, stmt.Column})
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in positions: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateInputContext(t *testing.T) {
+	input := "SELECT 1; SELECT '2;';\nSELECT 3"
+	got, err := SeparateInputContext(context.Background(), input)
+	if err != nil {
+		t.Fatalf("SeparateInputContext() returns error: %v", err)
+	}
+	if diff := cmp.Diff(SeparateInput(input), got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparateInputContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input := strings.Repeat(benchmarkInput, 100)
+	start := time.Now()
+	got, err := SeparateInputContext(ctx, input)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SeparateInputContext() returns error %v, but want = %v", err, context.Canceled)
+	}
+	if len(got) != 0 {
+		t.Errorf("SeparateInputContext() returns %d statements, but want none", len(got))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SeparateInputContext() takes %v after cancellation", elapsed)
+	}
+}
+
+func TestSeparateInputContext_CancelMidway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newSeparator(strings.Repeat("SELECT 1;", 10000), false, nil)
+	s.ctx = ctx
+	var n int
+	for {
+		if _, ok := s.Next(); !ok {
+			break
+		}
+		if n++; n == 10 {
+			cancel()
+		}
+	}
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, but want = %v", s.Err(), context.Canceled)
+	}
+	if n >= 10000 {
+		t.Errorf("all %d statements are returned after cancellation", n)
+	}
+}
+
+func TestSeparateInputFunc(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"SELECT 1; SELECT 2\\G SELECT 3",
+		"/* comment */ SELECT 1; -- comment\n;SELECT '2",
+	} {
+		var got []InputStatement
+		err := SeparateInputFunc(input, func(stmt InputStatement) error {
+			got = append(got, stmt)
+			return nil
+		}, `\G`)
+		if err != nil {
+			t.Errorf("SeparateInputFunc(%q) returns error: %v", input, err)
+		}
+		if diff := cmp.Diff(SeparateInput(input, `\G`), got); diff != "" {
+			t.Errorf("SeparateInputFunc(%q) differs from SeparateInput: (-want +got):\n%s", input, diff)
+		}
+	}
+}
+
+func TestSeparateInputFunc_StopEarly(t *testing.T) {
+	errStop := errors.New("stop")
+	var got []string
+	err := SeparateInputFunc("SELECT 1; SELECT 2; SELECT 3;", func(stmt InputStatement) error {
+		got = append(got, stmt.Statement)
+		if stmt.Statement == "SELECT 2" {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("SeparateInputFunc() returns error %v, but want = %v", err, errStop)
+	}
+	if diff := cmp.Diff([]string{"SELECT 1", "SELECT 2"}, got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_DollarQuoting(t *testing.T) {
+	for _, tt := range []struct {
+		desc          string
+		input         string
+		dollarQuoting bool
+		want          []string
+		wantStatus    Status
+	}{
+		{
+			desc:          "dollar-quoted string with tag",
+			input:         "CREATE FUNCTION f() AS $func$ SELECT 1; SELECT 2; $func$; SELECT 3",
+			dollarQuoting: true,
+			want:          []string{"CREATE FUNCTION f() AS $func$ SELECT 1; SELECT 2; $func$", "SELECT 3"},
+		},
+		{
+			desc:          "dollar-quoted string with empty tag",
+			input:         "SELECT $$a;b$$; SELECT 2",
+			dollarQuoting: true,
+			want:          []string{"SELECT $$a;b$$", "SELECT 2"},
+		},
+		{
+			desc:          "differently tagged delimiters",
+			input:         "SELECT $a$ $b$; $$;$b$ $a$; SELECT 2",
+			dollarQuoting: true,
+			want:          []string{"SELECT $a$ $b$; $$;$b$ $a$", "SELECT 2"},
+		},
+		{
+			desc:          "tag is case sensitive",
+			input:         "SELECT $a$;$A$;$a$;",
+			dollarQuoting: true,
+			want:          []string{"SELECT $a$;$A$;$a$"},
+		},
+		{
+			desc:          "comments and quotes in dollar-quoted string",
+			input:         "SELECT $$ -- ' \" ` /* \\$$;",
+			dollarQuoting: true,
+			want:          []string{"SELECT $$ -- ' \" ` /* \\$$"},
+		},
+		{
+			desc:          "not a dollar-quoted string",
+			input:         "SELECT $1, $a b$; SELECT 2",
+			dollarQuoting: true,
+			want:          []string{"SELECT $1, $a b$", "SELECT 2"},
+		},
+		{
+			desc:          "non-closed dollar-quoted string",
+			input:         "SELECT $tag$;$$;",
+			dollarQuoting: true,
+			want:          []string{"SELECT $tag$;$$;"},
+			wantStatus:    Status{WaitingString: "$tag$", WaitingKind: WaitingStringLiteral},
+		},
+		{
+			desc:  "disabled",
+			input: "SELECT $$a;b$$; SELECT 2",
+			want:  []string{"SELECT $$a", "b$$", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, gotStatus := NewSeparator(tt.input, WithPreserveComments(true), WithDollarQuoting(tt.dollarQuoting)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStatus, gotStatus); diff != "" {
+				t.Errorf("difference in status: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_HashComments(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		hashComments     bool
+		preserveComments bool
+		want             []string
+	}{
+		{
+			desc:         "enabled",
+			input:        "SELECT 1 #2;\nSELECT 3;",
+			hashComments: true,
+			want:         []string{"SELECT 1  SELECT 3"},
+		},
+		{
+			desc:  "disabled",
+			input: "SELECT 1 #2;\nSELECT 3;",
+			want:  []string{"SELECT 1 #2", "SELECT 3"},
+		},
+		{
+			desc:             "enabled in preserve mode",
+			input:            "SELECT 1 #2;\nSELECT 3;",
+			hashComments:     true,
+			preserveComments: true,
+			want:             []string{"SELECT 1 #2;\nSELECT 3"},
+		},
+		{
+			desc:  "disabled doesn't affect other comments",
+			input: "SELECT 1 #2 -- 3;\n/* ; */;",
+			want:  []string{"SELECT 1 #2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithHashComments(tt.hashComments), WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_NestedComments(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		nestedComments   bool
+		preserveComments bool
+		want             []string
+		wantStatus       Status
+	}{
+		{
+			desc:           "two levels of nesting",
+			input:          "SELECT /* a /* b; */ c; */ 1; SELECT 2",
+			nestedComments: true,
+			want:           []string{"SELECT   1", "SELECT 2"},
+		},
+		{
+			desc:           "multiple nested comments",
+			input:          "SELECT /* /* ; */ /* ; */ ; */1;",
+			nestedComments: true,
+			want:           []string{"SELECT  1"},
+		},
+		{
+			desc:             "two levels of nesting in preserve mode",
+			input:            "SELECT /* a /* b; */ c; */ 1; SELECT 2",
+			nestedComments:   true,
+			preserveComments: true,
+			want:             []string{"SELECT /* a /* b; */ c; */ 1", "SELECT 2"},
+		},
+		{
+			desc:           "non-closed nested comment",
+			input:          "SELECT /* a /* b; */ c; SELECT 2",
+			nestedComments: true,
+			want:           []string{"SELECT"},
+			wantStatus:     Status{WaitingString: "*/", WaitingKind: WaitingComment},
+		},
+		{
+			desc:             "non-closed nested comment in preserve mode",
+			input:            "SELECT /* a /* b; */ c; SELECT 2",
+			nestedComments:   true,
+			preserveComments: true,
+			want:             []string{"SELECT /* a /* b; */ c; SELECT 2"},
+			wantStatus:       Status{WaitingString: "*/", WaitingKind: WaitingComment},
+		},
+		{
+			desc:           "slash asterisk slash is not closed",
+			input:          "SELECT /*/ 1; */ 2;",
+			nestedComments: true,
+			want:           []string{"SELECT   2"},
+		},
+		{
+			desc:  "disabled",
+			input: "SELECT /* a /* b; */ c; */ 1; SELECT 2",
+			want:  []string{"SELECT   c", "*/ 1", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, gotStatus := NewSeparator(tt.input, WithNestedComments(tt.nestedComments), WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStatus, gotStatus); diff != "" {
+				t.Errorf("difference in status: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateInputStrict(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		want    []InputStatement
+		wantErr error
+	}{
+		{
+			desc:  "closed",
+			input: "SELECT 'a'; SELECT `b`\\G SELECT /* c */ 1",
+			want: []InputStatement{
+				{Statement: "SELECT 'a'", Terminator: ";"},
+				{Statement: "SELECT `b`", Terminator: `\G`},
+				{Statement: "SELECT   1", Terminator: ""},
+			},
+		},
+		{
+			desc:    "non-closed string",
+			input:   "SELECT 1; SELECT 'a;",
+			want:    []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+			wantErr: &UnclosedError{Offset: 17, Delimiter: "'", Kind: WaitingStringLiteral},
+		},
+		{
+			desc:    "non-closed triple-quoted string",
+			input:   `SELECT """a"";`,
+			wantErr: &UnclosedError{Offset: 7, Delimiter: `"""`, Kind: WaitingStringLiteral},
+		},
+		{
+			desc:    "non-closed bytes literal",
+			input:   `SELECT 1; SELECT b"\";`,
+			want:    []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+			wantErr: &UnclosedError{Offset: 17, Delimiter: `"`, Kind: WaitingStringLiteral},
+		},
+		{
+			desc:    "non-closed quoted identifier",
+			input:   "SELECT `テスト;",
+			wantErr: &UnclosedError{Offset: 7, Delimiter: "`", Kind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:    "non-closed comment",
+			input:   "SELECT 1; /* comment */ SELECT /* ;",
+			want:    []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+			wantErr: &UnclosedError{Offset: 31, Delimiter: "*/", Kind: WaitingComment},
+		},
+		{
+			desc:    "non-closed string with comment marker",
+			input:   "SELECT 'a -- b",
+			wantErr: &UnclosedError{Offset: 7, Delimiter: "'", Kind: WaitingStringLiteral, CommentMarker: "--"},
+		},
+		{
+			desc:    "non-closed string with multiple comment markers",
+			input:   "SELECT 1; SELECT \"a # b /* c",
+			want:    []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+			wantErr: &UnclosedError{Offset: 17, Delimiter: `"`, Kind: WaitingStringLiteral, CommentMarker: "#"},
+		},
+		{
+			desc:    "non-closed quoted identifier with comment marker",
+			input:   "SELECT `a -- b",
+			wantErr: &UnclosedError{Offset: 7, Delimiter: "`", Kind: WaitingQuotedIdentifier},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := SeparateInputStrict(tt.input, `\G`)
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantErr, err); diff != "" {
+				t.Errorf("difference in error: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnclosedError_Error(t *testing.T) {
+	for _, tt := range []struct {
+		err  *UnclosedError
+		want string
+	}{
+		{&UnclosedError{Offset: 7, Delimiter: `'''`}, `unclosed string literal at offset 7: expecting "'''"`},
+		{&UnclosedError{Offset: 7, Delimiter: "`"}, "unclosed quoted identifier at offset 7: expecting \"`\""},
+		{&UnclosedError{Offset: 7, Delimiter: "*/"}, `unclosed comment at offset 7: expecting "*/"`},
+		{&UnclosedError{Offset: 7, Delimiter: "-}", Kind: WaitingComment}, `unclosed comment at offset 7: expecting "-}"`},
+		{
+			&UnclosedError{Offset: 7, Delimiter: "'", Kind: WaitingStringLiteral, CommentMarker: "--"},
+			`unclosed string literal at offset 7: expecting "'" (the string contains comment marker "--", possibly after a stray quote)`,
+		},
+	} {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, but want = %q", got, tt.want)
+		}
+	}
+}
+
+func TestSeparateInputWithStatus(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		input      string
+		want       []string
+		wantStatus Status
+	}{
+		{
+			desc:       "closed",
+			input:      "SELECT 1; SELECT 'a' /* b */",
+			want:       []string{"SELECT 1", "SELECT 'a'"},
+			wantStatus: Status{},
+		},
+		{
+			desc:       "non-closed string",
+			input:      "SELECT 1; SELECT 'a",
+			want:       []string{"SELECT 1", "SELECT 'a"},
+			wantStatus: Status{WaitingString: "'", WaitingKind: WaitingStringLiteral},
+		},
+		{
+			desc:       "non-closed triple-quoted bytes",
+			input:      `SELECT b"""a`,
+			want:       []string{`SELECT b"""a`},
+			wantStatus: Status{WaitingString: `"""`, WaitingKind: WaitingStringLiteral},
+		},
+		{
+			desc:       "non-closed quoted identifier",
+			input:      "SELECT `a",
+			want:       []string{"SELECT `a"},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:       "non-closed comment",
+			input:      "SELECT 1 /* a",
+			want:       []string{"SELECT 1"},
+			wantStatus: Status{WaitingString: "*/", WaitingKind: WaitingComment},
+		},
+		{
+			desc:       "non-closed comment only",
+			input:      "/* a",
+			wantStatus: Status{WaitingString: "*/", WaitingKind: WaitingComment},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, gotStatus := SeparateInputWithStatus(tt.input)
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStatus, gotStatus); diff != "" {
+				t.Errorf("difference in status: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWaitingKind_String(t *testing.T) {
+	for _, tt := range []struct {
+		kind WaitingKind
+		want string
+	}{
+		{WaitingNone, "none"},
+		{WaitingStringLiteral, "string literal"},
+		{WaitingQuotedIdentifier, "quoted identifier"},
+		{WaitingComment, "comment"},
+		{WaitingKind(100), "WaitingKind(100)"},
+	} {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("WaitingKind(%d).String() = %q, but want = %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
+
+func TestSeparateInput_EscapedBacktick(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		input      string
+		want       []InputStatement
+		wantStatus Status
+	}{
+		{
+			desc:  "backslash escaped backtick followed by terminator",
+			input: "SELECT `a\\`;b`; SELECT 2\\G",
+			want: []InputStatement{
+				{Statement: "SELECT `a\\`;b`", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "doubled backtick followed by terminator",
+			input: "SELECT `a``;b`; SELECT `c``\\G`\\G",
+			want: []InputStatement{
+				{Statement: "SELECT `a``;b`", Terminator: ";"},
+				{Statement: "SELECT `c``\\G`", Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "non-closed after doubled backtick",
+			input: "SELECT `a``;",
+			want: []InputStatement{
+				{Statement: "SELECT `a``;", Terminator: ""},
+			},
+			wantStatus: Status{WaitingString: "`", WaitingKind: WaitingQuotedIdentifier},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, gotStatus := SeparateInputWithStatus(tt.input, `\G`)
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStatus, gotStatus); diff != "" {
+				t.Errorf("difference in status: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_KeepTerminator(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "semicolon",
+			input: "SELECT 1;",
+			want:  []InputStatement{{Statement: "SELECT 1;", Terminator: ";"}},
+		},
+		{
+			desc:  "custom terminator",
+			input: `SELECT 1\G`,
+			want:  []InputStatement{{Statement: `SELECT 1\G`, Terminator: `\G`}},
+		},
+		{
+			desc:  "whitespaces and comments before terminator",
+			input: "SELECT 1 /* comment */ ;",
+			want:  []InputStatement{{Statement: "SELECT 1;", Terminator: ";"}},
+		},
+		{
+			desc:  "empty statement",
+			input: "SELECT 1; ;",
+			want: []InputStatement{
+				{Statement: "SELECT 1;", Terminator: ";"},
+				{Statement: ";", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "unterminated trailing statement",
+			input: `SELECT 1\G SELECT 2`,
+			want: []InputStatement{
+				{Statement: `SELECT 1\G`, Terminator: `\G`},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithTerminators(`\G`), WithKeepTerminator(true)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_TrimSpace(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		preserveComments bool
+		want             []InputStatement
+	}{
+		{
+			desc:             "whitespaces around statements",
+			input:            "  SELECT 1 ;\n\tSELECT 2\\G\n",
+			preserveComments: true,
+			want: []InputStatement{
+				{Statement: "  SELECT 1 ", Terminator: ";"},
+				{Statement: "\n\tSELECT 2", Terminator: `\G`},
+				{Statement: "\n", Terminator: ""},
+			},
+		},
+		{
+			desc:             "comments and empty statements",
+			input:            "-- comment\nSELECT 1; ;\n/* comment */ SELECT 2 # comment",
+			preserveComments: true,
+			want: []InputStatement{
+				{Statement: "-- comment\nSELECT 1", Terminator: ";"},
+				{Statement: " ", Terminator: ";"},
+				{Statement: "\n/* comment */ SELECT 2 # comment", Terminator: ""},
+			},
+		},
+		{
+			desc:             "multi-line strings",
+			input:            "SELECT '''\n a\n ''';\n",
+			preserveComments: true,
+			want: []InputStatement{
+				{Statement: "SELECT '''\n a\n '''", Terminator: ";"},
+				{Statement: "\n", Terminator: ""},
+			},
+		},
+		{
+			desc:  "comments are replaced in strip mode",
+			input: "  SELECT 1 -- comment\n; SELECT 2 ",
+			want: []InputStatement{
+				{Statement: "  SELECT 1  ", Terminator: ";"},
+				{Statement: " SELECT 2 ", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithTerminators(`\G`), WithPreserveComments(tt.preserveComments), WithTrimSpace(false)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if !tt.preserveComments {
+				return
+			}
+			var sb strings.Builder
+			for _, stmt := range got {
+				sb.WriteString(stmt.Statement + stmt.Terminator)
+			}
+			if joined := sb.String(); joined != tt.input {
+				t.Errorf("joined statements = %q, but want = %q", joined, tt.input)
+			}
+		})
+	}
+}
+
+// benchmarkInput is a large input which mostly consists of ASCII characters.
+var benchmarkInput = strings.Repeat(`-- comment
+CREATE TABLE Singers (
+  SingerId INT64 NOT NULL, /* comment */
+  Name STRING(MAX) DEFAULT ("テスト"),
+) PRIMARY KEY (SingerId);
+INSERT INTO Singers (SingerId, Name) VALUES (1, 'a;b'), (2, r"c\d"), (3, """e;
+f""");
+SELECT * FROM Singers WHERE Name = b'\x00' # comment
+\G
+`, 1000)
+
+func BenchmarkSeparateInput(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SeparateInput(benchmarkInput, `\G`)
+	}
+}
+
+func BenchmarkSeparateInputPreserveComments(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SeparateInputPreserveComments(benchmarkInput, `\G`)
+	}
+}
+
+func TestSeparateInput_InvalidUTF8(t *testing.T) {
+	input := "SELECT '\xff;'; SELECT \xfe\xff;"
+	want := []InputStatement{
+		{Statement: "SELECT '\xff;'", Terminator: ";", Start: 0, End: 11, Line: 1, Column: 1, TerminatorOffset: 11, Terminated: true},
+		{Statement: "SELECT \xfe\xff", Terminator: ";", Start: 13, End: 22, Line: 1, Column: 14, TerminatorOffset: 22, Terminated: true, Index: 1},
+	}
+	if diff := cmp.Diff(want, SeparateInput(input)); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			want:  "",
+		},
+		{
+			desc:  "whitespaces around statements",
+			input: "  SELECT 1 ;\n\tSELECT 2\\G\n",
+			want:  "SELECT 1;SELECT 2\\G",
+		},
+		{
+			desc:  "comments",
+			input: "-- comment\nSELECT 1 /* comment */ + 2; # comment\nSELECT 3",
+			want:  "-- comment\nSELECT 1 /* comment */ + 2;# comment\nSELECT 3",
+		},
+		{
+			desc:  "empty statements",
+			input: "SELECT 1; ;",
+			want:  "SELECT 1;;",
+		},
+		{
+			desc:  "strings and quoted identifiers",
+			input: "SELECT ';', '''\n;\n''', `a;b`;\nSELECT 2",
+			want:  "SELECT ';', '''\n;\n''', `a;b`;SELECT 2",
+		},
+		{
+			desc:  "non-closed string",
+			input: "SELECT 1; SELECT '2;",
+			want:  "SELECT 1;SELECT '2;",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			stmts := SeparateInputPreserveComments(tt.input, `\G`)
+			joined := Join(stmts)
+			if joined != tt.want {
+				t.Errorf("Join() = %q, but want = %q", joined, tt.want)
+			}
+			if diff := cmp.Diff(stmts, SeparateInputPreserveComments(joined, `\G`), ignorePositions); diff != "" {
+				t.Errorf("difference in statements separated again: (-want +got):\n%s", diff)
+			}
+
+			// round-trip
+			stmts, err := NewSeparator(tt.input, WithTerminators(`\G`), WithPreserveComments(true), WithTrimSpace(false)).ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll() returns error: %v", err)
+			}
+			if got := Join(stmts); got != tt.input {
+				t.Errorf("Join() without trimming = %q, but want = %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestWriteSeparated(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		sep   string
+		want  string
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			sep:   ";\n",
+			want:  "",
+		},
+		{
+			desc:  "multiple statements",
+			input: "  SELECT 1 ;\n\tSELECT 2\\G -- comment\n;;SELECT 'a;'",
+			sep:   ";\n",
+			want:  "SELECT 1;\nSELECT 2;\nSELECT 'a;';\n",
+		},
+		{
+			desc:  "custom separator",
+			input: "SELECT 1; SELECT 2",
+			sep:   "\nGO\n",
+			want:  "SELECT 1\nGO\nSELECT 2\nGO\n",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteSeparated(&sb, tt.input, tt.sep, `\G`); err != nil {
+				t.Fatalf("WriteSeparated() returns error: %v", err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("WriteSeparated() writes %q, but want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSeparated_Error(t *testing.T) {
+	errWrite := errors.New("write error")
+	w := &failingWriter{n: 1, err: errWrite}
+	if err := WriteSeparated(w, "SELECT 1; SELECT 2; SELECT 3", ";\n"); !errors.Is(err, errWrite) {
+		t.Errorf("WriteSeparated() returns error %v, but want = %v", err, errWrite)
+	}
+	if got, want := w.sb.String(), "SELECT 1;\n"; got != want {
+		t.Errorf("WriteSeparated() writes %q, but want = %q", got, want)
+	}
+}
+
+// failingWriter fails with err after n writes.
+type failingWriter struct {
+	sb  strings.Builder
+	n   int
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, w.err
+	}
+	w.n--
+	return w.sb.Write(p)
+}
+
+func TestCountStatements(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"SELECT 1",
+		"SELECT 1;",
+		"SELECT 1; ;;SELECT 2\\G",
+		"-- comment\nSELECT 1; /* comment */",
+		"SELECT ';'; SELECT `;`; SELECT 1 -- ;",
+		"SELECT 1; SELECT '2;",
+		benchmarkInput,
+	} {
+		if got, want := CountStatements(input, `\G`), len(SeparateInput(input, `\G`)); got != want {
+			t.Errorf("CountStatements(%q) = %d, but want = %d", input, got, want)
+		}
+	}
+}
+
+func TestTerminatorStats(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  map[string]int
+	}{
+		{
+			input: "",
+			want:  map[string]int{},
+		},
+		{
+			input: "SELECT 1",
+			want:  map[string]int{"": 1},
+		},
+		{
+			input: "SELECT 1; SELECT 2\\G\nSELECT ';'; SELECT '\\G' -- ;\n;;SELECT 3\\G SELECT 4",
+			want:  map[string]int{";": 4, `\G`: 2, "": 1},
+		},
+		{
+			input: "SELECT 1; SELECT '2;",
+			want:  map[string]int{";": 1, "": 1},
+		},
+	} {
+		if diff := cmp.Diff(tt.want, TerminatorStats(tt.input, `\G`)); diff != "" {
+			t.Errorf("%q: difference in stats: (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func BenchmarkCountStatements(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CountStatements(benchmarkInput, `\G`)
+	}
+}
+
+func BenchmarkSeparateInput_Len(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = len(SeparateInput(benchmarkInput, `\G`))
+	}
+}
+
+func BenchmarkSeparator_Next(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := NewSeparator(benchmarkInput, WithTerminators(`\G`))
+		for _, ok := s.Next(); ok; _, ok = s.Next() {
+		}
+	}
+}
+
+func TestSeparator_DelimiterCommand(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		delimiterCommand bool
+		want             []InputStatement
+	}{
+		{
+			desc: "procedure body with semicolons",
+			input: `DELIMITER //
+CREATE PROCEDURE p()
+BEGIN
+  SELECT 1;
+  SELECT 2;
+END//
+DELIMITER ;
+SELECT 3;
+`,
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND", Terminator: "//"},
+				{Statement: "SELECT 3", Terminator: ";"},
+			},
+		},
+		{
+			desc:             "case-insensitive and whitespaces",
+			input:            "delimiter  $$  \nSELECT 1; SELECT 2$$\n\tDeLiMiTeR\t;\nSELECT 3;",
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "SELECT 1; SELECT 2", Terminator: "$$"},
+				{Statement: "SELECT 3", Terminator: ";"},
+			},
+		},
+		{
+			desc:             "after comments",
+			input:            "-- comment\nDELIMITER //\nSELECT 1;//",
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "SELECT 1;", Terminator: "//"},
+			},
+		},
+		{
+			desc:             "not at the beginning of a statement",
+			input:            "SELECT DELIMITER //\n; SELECT 1//",
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "SELECT DELIMITER //", Terminator: ";"},
+				{Statement: "SELECT 1//", Terminator: ""},
+			},
+		},
+		{
+			desc:             "without token",
+			input:            "DELIMITER\nSELECT 1;",
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "DELIMITER\nSELECT 1", Terminator: ";"},
+			},
+		},
+		{
+			desc:             "at the end of input",
+			input:            "SELECT 1;\nDELIMITER //",
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+			},
+		},
+		{
+			desc:             "custom terminators are still active",
+			input:            "DELIMITER //\nSELECT 1;\\GSELECT 2//",
+			delimiterCommand: true,
+			want: []InputStatement{
+				{Statement: "SELECT 1;", Terminator: `\G`},
+				{Statement: "SELECT 2", Terminator: "//"},
+			},
+		},
+		{
+			desc:  "disabled",
+			input: "DELIMITER //\nSELECT 1;//",
+			want: []InputStatement{
+				{Statement: "DELIMITER //\nSELECT 1", Terminator: ";"},
+				{Statement: "//", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithTerminators(`\G`), WithDelimiterCommand(tt.delimiterCommand)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_BatchSeparator(t *testing.T) {
+	for _, tt := range []struct {
+		desc           string
+		input          string
+		batchSeparator string
+		want           []InputStatement
+	}{
+		{
+			desc:           "batches",
+			input:          "SELECT 1\nGO\nSELECT 2\n  go  \nSELECT 3\nGo",
+			batchSeparator: "GO",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: "GO"},
+				{Statement: "SELECT 2", Terminator: "GO"},
+				{Statement: "SELECT 3", Terminator: "GO"},
+			},
+		},
+		{
+			desc:           "lowercase word",
+			input:          "SELECT 1\r\nGO\r\nSELECT 2",
+			batchSeparator: "go",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: "GO"},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+		{
+			desc:           "not the only token in the line",
+			input:          "SELECT GO FROM t\nGO SELECT 1\nGOTO\nSELECT 1 GO\nGO",
+			batchSeparator: "GO",
+			want: []InputStatement{
+				{Statement: "SELECT GO FROM t\nGO SELECT 1\nGOTO\nSELECT 1 GO", Terminator: "GO"},
+			},
+		},
+		{
+			desc:           "in strings and quoted identifiers",
+			input:          "SELECT '''\nGO\n''', `\nGO\n`\nGO",
+			batchSeparator: "GO",
+			want: []InputStatement{
+				{Statement: "SELECT '''\nGO\n''', `\nGO\n`", Terminator: "GO"},
+			},
+		},
+		{
+			desc:           "after a BOM",
+			input:          "\uFEFFGO\nSELECT 1",
+			batchSeparator: "GO",
+			want: []InputStatement{
+				{Statement: "", Terminator: "GO"},
+				{Statement: "SELECT 1", Terminator: ""},
+			},
+		},
+		{
+			desc:           "with semicolons",
+			input:          "SELECT 1;\nSELECT 2\nGO\n",
+			batchSeparator: "GO",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: "GO"},
+			},
+		},
+		{
+			desc:  "disabled",
+			input: "SELECT 1\nGO\nSELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1\nGO\nSELECT 2", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithBatchSeparator(tt.batchSeparator)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateInput_BOM(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "BOM at the beginning",
+			input: "\uFEFFSELECT 1;",
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";", Start: 3, End: 11, Line: 1, Column: 1, TerminatorOffset: 11, Terminated: true}},
+		},
+		{
+			desc:  "BOM in the middle",
+			input: "SELECT 1;\uFEFFSELECT 2;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 0, End: 8, Line: 1, Column: 1, TerminatorOffset: 8, Terminated: true},
+				{Statement: "\uFEFFSELECT 2", Terminator: ";", Start: 9, End: 20, Line: 1, Column: 10, TerminatorOffset: 20, Terminated: true, Index: 1},
+			},
+		},
+		{
+			desc:  "only a single BOM is skipped",
+			input: "\uFEFF\uFEFFSELECT 1;",
+			want:  []InputStatement{{Statement: "\uFEFFSELECT 1", Terminator: ";", Start: 3, End: 14, Line: 1, Column: 1, TerminatorOffset: 14, Terminated: true}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, SeparateInput(tt.input)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_SingleLineCommentNewlines(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		preserveComments bool
+		want             []string
+	}{
+		{
+			desc:  "LF",
+			input: "SELECT 1 -- comment\n, 2 # comment\n;",
+			want:  []string{"SELECT 1  , 2"},
+		},
+		{
+			desc:  "CRLF",
+			input: "SELECT 1 -- comment\r\n, 2 # comment\r\n;",
+			want:  []string{"SELECT 1  , 2"},
+		},
+		{
+			desc:  "CR",
+			input: "SELECT 1 -- comment\r, 2 # comment\r;",
+			want:  []string{"SELECT 1  , 2"},
+		},
+		{
+			desc:             "LF in preserve mode",
+			input:            "SELECT 1 -- comment\n, 2 # comment\n;",
+			preserveComments: true,
+			want:             []string{"SELECT 1 -- comment\n, 2 # comment"},
+		},
+		{
+			desc:             "CRLF in preserve mode",
+			input:            "SELECT 1 -- comment\r\n, 2 # comment\r\n;",
+			preserveComments: true,
+			want:             []string{"SELECT 1 -- comment\r\n, 2 # comment"},
+		},
+		{
+			desc:             "CR in preserve mode",
+			input:            "SELECT 1 -- comment\r, 2 # comment\r;",
+			preserveComments: true,
+			want:             []string{"SELECT 1 -- comment\r, 2 # comment"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparatorSkipComments_Newlines(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		str              string
+		preserveComments bool
+		want             string
+		wantRemained     string
+	}{
+		{"LF", "-- comment\nSELECT 1", false, " ", "SELECT 1"},
+		{"CRLF", "-- comment\r\nSELECT 1", false, " ", "SELECT 1"},
+		{"CR", "# comment\rSELECT 1", false, " ", "SELECT 1"},
+		{"LF in preserve mode", "-- comment\nSELECT 1", true, "-- comment\n", "SELECT 1"},
+		{"CRLF in preserve mode", "-- comment\r\nSELECT 1", true, "-- comment\r\n", "SELECT 1"},
+		{"CR in preserve mode", "# comment\rSELECT 1", true, "# comment\r", "SELECT 1"},
+		{"CR in multiline comment", "/* comment\r */SELECT 1", true, "/* comment\r */", "SELECT 1"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := newSeparator(tt.str, tt.preserveComments, nil)
+			s.skipComments()
+
+			if got := s.sb.String(); got != tt.want {
+				t.Errorf("skipComments(%q) = %q, but want = %q", tt.str, got, tt.want)
+			}
+			if remained := s.str; remained != tt.wantRemained {
+				t.Errorf("skipComments(%q) remained %q, but want = %q", tt.str, remained, tt.wantRemained)
+			}
+		})
+	}
+}
+
+func TestSeparator_SkipEmpty(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		opts  []Option
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "consecutive semicolons",
+			input: "SELECT 1; ;;\n;SELECT 2;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "comment-only statements",
+			input: "SELECT 1; -- comment\n; /* comment */; SELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+		{
+			desc:  "keep terminator",
+			opts:  []Option{WithKeepTerminator(true), WithTerminators(`\G`)},
+			input: "SELECT 1;\\G ; SELECT 2\\G",
+			want: []InputStatement{
+				{Statement: "SELECT 1;", Terminator: ";"},
+				{Statement: `SELECT 2\G`, Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "trailing whitespace-only segment without trimming",
+			opts:  []Option{WithTrimSpace(false)},
+			input: "SELECT 1; ;\n\t",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "only empty statements",
+			input: " ; ;",
+			want:  nil,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, append(tt.opts, WithSkipEmpty(true))...).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_CaseInsensitiveTerminators(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		terms []string
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "mixed cases",
+			terms: []string{"go"},
+			input: "SELECT 1 Go SELECT 2 GO SELECT 3 go SELECT 4 gO",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: "go"},
+				{Statement: "SELECT 2", Terminator: "go"},
+				{Statement: "SELECT 3", Terminator: "go"},
+				{Statement: "SELECT 4", Terminator: "go"},
+			},
+		},
+		{
+			desc:  "not a part of identifiers",
+			terms: []string{"GO"},
+			input: "SELECT gopher, ago, _go, go_, go1 FROM t GO\nSELECT 2;",
+			want: []InputStatement{
+				{Statement: "SELECT gopher, ago, _go, go_, go1 FROM t", Terminator: "GO"},
+				{Statement: "SELECT 2", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "not in strings and comments",
+			terms: []string{"go"},
+			input: "SELECT 'go', `GO` -- go\nGO",
+			want: []InputStatement{
+				{Statement: "SELECT 'go', `GO`", Terminator: "go"},
+			},
+		},
+		{
+			desc:  "symbolic terminators",
+			terms: []string{`\g`},
+			input: `SELECT 1\G SELECT 2\g`,
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\g`},
+				{Statement: "SELECT 2", Terminator: `\g`},
+			},
+		},
+		{
+			desc:  "multi-byte characters",
+			terms: []string{"ǅ"},
+			input: "SELECT 1 Ǆ SELECT 2 ǆ SELECT 3 ǅ SELECT 4 Kǆ",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: "ǅ"},
+				{Statement: "SELECT 2", Terminator: "ǅ"},
+				{Statement: "SELECT 3", Terminator: "ǅ"},
+				{Statement: "SELECT 4 Kǆ", Terminator: ""},
+			},
+		},
+		{
+			desc:  "semicolons are not affected",
+			terms: []string{"go"},
+			input: "SELECT 1;SELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithTerminators(tt.terms...), WithCaseInsensitiveTerminators(true)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_EscapeStringPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		enabled bool
+		input   string
+		want    []string
+	}{
+		{
+			desc:    "escaped single quote",
+			enabled: true,
+			input:   `SELECT E'a\';b'; SELECT 2`,
+			want:    []string{`SELECT E'a\';b'`, "SELECT 2"},
+		},
+		{
+			desc:    "lower case prefix and double quotes",
+			enabled: true,
+			input:   `SELECT e"x;\"y"; SELECT 2`,
+			want:    []string{`SELECT e"x;\"y"`, "SELECT 2"},
+		},
+		{
+			desc:    "triple-quoted string",
+			enabled: true,
+			input:   "SELECT E'''a;\nb'''; SELECT 2",
+			want:    []string{"SELECT E'''a;\nb'''", "SELECT 2"},
+		},
+		{
+			desc:    "identifiers starting with e",
+			enabled: true,
+			input:   "SELECT e, email FROM t WHERE e=1; SELECT 2",
+			want:    []string{"SELECT e, email FROM t WHERE e=1", "SELECT 2"},
+		},
+		{
+			desc:    "identifier ending with e followed by a string",
+			enabled: true,
+			input:   `SELECT name'a\';'; SELECT 2`,
+			want:    []string{`SELECT name'a\';'`, "SELECT 2"},
+		},
+		{
+			desc:  "disabled",
+			input: `SELECT E'a\';b'; SELECT 2`,
+			want:  []string{`SELECT E'a\';b'`, "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithEscapeStringPrefix(tt.enabled)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_LineCommentPrefixes(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		prefixes         []string
+		input            string
+		preserveComments bool
+		want             []string
+	}{
+		{
+			desc:     "double slash comments",
+			prefixes: []string{"//"},
+			input:    "SELECT 1 // comment;\n; // comment; SELECT 2\nSELECT 3",
+			want:     []string{"SELECT 1", "SELECT 3"},
+		},
+		{
+			desc:             "double slash comments in preserve mode",
+			prefixes:         []string{"//"},
+			input:            "SELECT 1 // comment;\n; // comment; SELECT 2\nSELECT 3",
+			preserveComments: true,
+			want:             []string{"SELECT 1 // comment;", "// comment; SELECT 2\nSELECT 3"},
+		},
+		{
+			desc:     "double semicolon comments",
+			prefixes: []string{";;"},
+			input:    "SELECT 1;;; comment\n;SELECT 2;\r\n;; comment; \r\nSELECT 3",
+			want:     []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+		},
+		{
+			desc:     "comments are replaced by whitespaces",
+			prefixes: []string{"//"},
+			input:    "SELECT 1// comment\n+2",
+			want:     []string{"SELECT 1 +2"},
+		},
+		{
+			desc:     "not in strings",
+			prefixes: []string{"//"},
+			input:    "SELECT 'http://example.com'; SELECT 2",
+			want:     []string{"SELECT 'http://example.com'", "SELECT 2"},
+		},
+		{
+			desc:     "longest prefix",
+			prefixes: []string{"/", "---"},
+			input:    "SELECT 1 /* a;\n */; SELECT 2 / comment;\n;",
+			want:     []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:     "empty prefixes are ignored",
+			prefixes: []string{""},
+			input:    "SELECT 1; SELECT 2",
+			want:     []string{"SELECT 1", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithLineCommentPrefixes(tt.prefixes...), WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_BlockCommentDelimiters(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		opts             []Option
+		input            string
+		preserveComments bool
+		want             []string
+		wantStatus       Status
+	}{
+		{
+			desc:  "custom delimiters",
+			opts:  []Option{WithBlockCommentDelimiters("{-", "-}")},
+			input: "SELECT {- comment; -} 1; {- comment -}SELECT 2; /* SELECT 3; */",
+			want:  []string{"SELECT   1", "SELECT 2", "/* SELECT 3", "*/"},
+		},
+		{
+			desc:             "custom delimiters in preserve mode",
+			opts:             []Option{WithBlockCommentDelimiters("{-", "-}")},
+			input:            "SELECT {- comment; -} 1; {- comment -}SELECT 2",
+			preserveComments: true,
+			want:             []string{"SELECT {- comment; -} 1", "{- comment -}SELECT 2"},
+		},
+		{
+			desc:       "non-closed custom comment",
+			opts:       []Option{WithBlockCommentDelimiters("{-", "-}")},
+			input:      "SELECT 1; SELECT 2 {- comment; */",
+			want:       []string{"SELECT 1", "SELECT 2"},
+			wantStatus: Status{WaitingString: "-}", WaitingKind: WaitingComment},
+		},
+		{
+			desc:  "nested custom comments",
+			opts:  []Option{WithBlockCommentDelimiters("{-", "-}"), WithNestedComments(true)},
+			input: "SELECT 1 {- {- ; -} ; -}; SELECT 2",
+			want:  []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:  "default delimiters",
+			input: "SELECT /* comment; */ 1; {- comment; -}",
+			want:  []string{"SELECT   1", "{- comment", "-}"},
+		},
+		{
+			desc:  "empty delimiters are ignored",
+			opts:  []Option{WithBlockCommentDelimiters("", "")},
+			input: "SELECT /* comment; */ 1",
+			want:  []string{"SELECT   1"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, status := NewSeparator(tt.input, append(tt.opts, WithPreserveComments(tt.preserveComments))...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStatus, status); diff != "" {
+				t.Errorf("difference in status: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_TerminatorRegexp(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		re    *regexp.Regexp
+		terms []string
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "backslash g",
+			re:    regexp.MustCompile(`\\[gG]`),
+			input: `SELECT 1\g SELECT 2\G SELECT '\g'; SELECT 3`,
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\g`},
+				{Statement: "SELECT 2", Terminator: `\G`},
+				{Statement: `SELECT '\g'`, Terminator: ";"},
+				{Statement: "SELECT 3", Terminator: ""},
+			},
+		},
+		{
+			desc:  "whitespace-flexible pattern",
+			re:    regexp.MustCompile(`\\\s*G`),
+			input: "SELECT 1\\  G SELECT 2\\\nG",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\  G`},
+				{Statement: "SELECT 2", Terminator: "\\\nG"},
+			},
+		},
+		{
+			desc:  "not in comments",
+			re:    regexp.MustCompile(`\\[gG]`),
+			input: "SELECT 1 -- \\G\n\\G",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "custom terminators take precedence",
+			re:    regexp.MustCompile(`\\G+`),
+			terms: []string{`\G`},
+			input: `SELECT 1\GG`,
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\G`},
+				{Statement: "G", Terminator: ""},
+			},
+		},
+		{
+			desc:  "zero-length matches are ignored",
+			re:    regexp.MustCompile(`x*`),
+			input: "SELECT 1; SELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+		{
+			desc:  "alternation is anchored as a whole",
+			re:    regexp.MustCompile(`GO|\\G`),
+			input: `SELECT 1GO SELECT 2\G`,
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: "GO"},
+				{Statement: "SELECT 2", Terminator: `\G`},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithTerminators(tt.terms...), WithTerminatorRegexp(tt.re)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_MaxStatements(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		max     int
+		opts    []Option
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{
+			desc:  "exactly at the limit",
+			max:   3,
+			input: "SELECT 1; SELECT 2; SELECT 3",
+			want:  []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+		},
+		{
+			desc:  "terminated at the limit",
+			max:   3,
+			input: "SELECT 1; SELECT 2; SELECT 3;",
+			want:  []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+		},
+		{
+			desc:    "one over the limit",
+			max:     3,
+			input:   "SELECT 1; SELECT 2; SELECT 3; SELECT 4",
+			want:    []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+			wantErr: &TooManyStatementsError{Max: 3, Count: 4},
+		},
+		{
+			desc:    "empty statements are counted",
+			max:     1,
+			input:   "SELECT 1; ;",
+			want:    []string{"SELECT 1"},
+			wantErr: &TooManyStatementsError{Max: 1, Count: 2},
+		},
+		{
+			desc:  "skipped empty statements are not counted",
+			max:   1,
+			opts:  []Option{WithSkipEmpty(true)},
+			input: "SELECT 1; ;",
+			want:  []string{"SELECT 1"},
+		},
+		{
+			desc:  "not limited",
+			input: "SELECT 1; SELECT 2",
+			want:  []string{"SELECT 1", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewSeparator(tt.input, append(tt.opts, WithMaxStatements(tt.max))...).ReadAll()
+			if diff := cmp.Diff(tt.wantErr, err); diff != "" {
+				t.Errorf("difference in error: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTooManyStatementsError_Error(t *testing.T) {
+	err := &TooManyStatementsError{Max: 3, Count: 4}
+	if got, want := err.Error(), "too many statements: 4 statements exceed the limit of 3"; got != want {
+		t.Errorf("Error() = %q, but want = %q", got, want)
+	}
+}
+
+func TestSeparator_MaxStatementLength(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		max     int
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{
+			desc:  "exactly at the limit",
+			max:   8,
+			input: "SELECT 1; SELECT 2;",
+			want:  []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:    "over the limit",
+			max:     8,
+			input:   "SELECT 1; SELECT 10;",
+			want:    []string{"SELECT 1"},
+			wantErr: &StatementTooLongError{Offset: 10, Max: 8},
+		},
+		{
+			desc:  "counted in runes",
+			max:   10,
+			input: "SELECT 'あ'; SELECT 'い';",
+			want:  []string{"SELECT 'あ'", "SELECT 'い'"},
+		},
+		{
+			desc:    "huge unterminated string",
+			max:     100,
+			input:   "SELECT 1; SELECT '" + strings.Repeat("a;", 10000),
+			want:    []string{"SELECT 1"},
+			wantErr: &StatementTooLongError{Offset: 10, Max: 100},
+		},
+		{
+			desc:  "not limited",
+			input: "SELECT '" + strings.Repeat("a;", 10000) + "';",
+			want:  []string{"SELECT '" + strings.Repeat("a;", 10000) + "'"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewSeparator(tt.input, WithMaxStatementLength(tt.max)).ReadAll()
+			if diff := cmp.Diff(tt.wantErr, err); diff != "" {
+				t.Errorf("difference in error: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStatementTooLongError_Error(t *testing.T) {
+	err := &StatementTooLongError{Offset: 10, Max: 100}
+	if got, want := err.Error(), "statement at offset 10 exceeds the limit of 100 characters"; got != want {
+		t.Errorf("Error() = %q, but want = %q", got, want)
+	}
+}
+
+func TestSeparator_StrictEscapes(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{
+			desc:  "valid escapes",
+			input: `SELECT '\a\b\f\n\r\t\v\\\?\"\'\x41\X4a\101\377\u00e9\U0001F600'; SELECT b"\x00\xFF\000\n"; SELECT 3`,
+			want:  []string{`SELECT '\a\b\f\n\r\t\v\\\?\"\'\x41\X4a\101\377\u00e9\U0001F600'`, `SELECT b"\x00\xFF\000\n"`, "SELECT 3"},
+		},
+		{
+			desc:  "valid escapes in triple-quoted strings",
+			input: `SELECT '''\'''\x41'''; SELECT 2`,
+			want:  []string{`SELECT '''\'''\x41'''`, "SELECT 2"},
+		},
+		{
+			desc:    "malformed hex escape in bytes literal",
+			input:   `SELECT 1; SELECT b'\x4'; SELECT 3`,
+			want:    []string{"SELECT 1"},
+			wantErr: &InvalidEscapeError{Offset: 19, Sequence: `\x`},
+		},
+		{
+			desc:    "unknown escape",
+			input:   `SELECT 'a\d'`,
+			wantErr: &InvalidEscapeError{Offset: 9, Sequence: `\d`},
+		},
+		{
+			desc:    "octal escape out of range",
+			input:   `SELECT '\400'`,
+			wantErr: &InvalidEscapeError{Offset: 8, Sequence: `\4`},
+		},
+		{
+			desc:    "short octal escape",
+			input:   `SELECT '\12'`,
+			wantErr: &InvalidEscapeError{Offset: 8, Sequence: `\1`},
+		},
+		{
+			desc:    "short unicode escape",
+			input:   `SELECT '\u12'`,
+			wantErr: &InvalidEscapeError{Offset: 8, Sequence: `\u`},
+		},
+		{
+			desc:    "surrogate in unicode escape",
+			input:   `SELECT '\uD800'`,
+			wantErr: &InvalidEscapeError{Offset: 8, Sequence: `\u`},
+		},
+		{
+			desc:    "out of range unicode escape",
+			input:   `SELECT '\U00110000'`,
+			wantErr: &InvalidEscapeError{Offset: 8, Sequence: `\U`},
+		},
+		{
+			desc:    "unicode escape in bytes literal",
+			input:   `SELECT b'\u0041'`,
+			wantErr: &InvalidEscapeError{Offset: 9, Sequence: `\u`},
+		},
+		{
+			desc:    "multi-byte character after backslash",
+			input:   `SELECT 'テ\ス'`,
+			wantErr: &InvalidEscapeError{Offset: 11, Sequence: `\ス`},
+		},
+		{
+			desc:  "raw literals are exempt",
+			input: `SELECT r'\d\x', rb'\u', br"\4"; SELECT 2`,
+			want:  []string{`SELECT r'\d\x', rb'\u', br"\4"`, "SELECT 2"},
+		},
+		{
+			desc:  "comments and quoted identifiers are exempt",
+			input: "SELECT `\\d` -- '\\d'\n; SELECT 2",
+			want:  []string{"SELECT `\\d`", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := SeparateInputStrict(tt.input)
+			if diff := cmp.Diff(tt.wantErr, err); diff != "" {
+				t.Errorf("difference in error: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+
+			// lenient functions don't validate escape sequences
+			if _, err := NewSeparator(tt.input, WithStrict(true)).ReadAll(); err != nil {
+				t.Errorf("ReadAll() without WithStrictEscapes returns error: %v", err)
+			}
+		})
+	}
+}
+
+func TestInvalidEscapeError_Error(t *testing.T) {
+	err := &InvalidEscapeError{Offset: 9, Sequence: `\d`}
+	if got, want := err.Error(), `invalid escape sequence "\\d" at offset 9`; got != want {
+		t.Errorf("Error() = %q, but want = %q", got, want)
+	}
+}
+
+func TestSeparateSQL(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []InputStatement
+	}{
+		{
+			desc:  "vertical terminator",
+			input: `SELECT "123"\G`,
+			opts:  []Option{WithVerticalTerminator()},
+			want:  []InputStatement{{Statement: `SELECT "123"`, Terminator: `\G`}},
+		},
+		{
+			desc:  "mixed terminators",
+			input: `SELECT "123"; SELECT "456"\G SELECT "789";`,
+			opts:  []Option{WithVerticalTerminator()},
+			want: []InputStatement{
+				{Statement: `SELECT "123"`, Terminator: ";"},
+				{Statement: `SELECT "456"`, Terminator: `\G`},
+				{Statement: `SELECT "789"`, Terminator: ";"},
+			},
+		},
+		{
+			desc:  "vertical terminator in strings",
+			input: `SELECT "1\G2"\G SELECT '\G'\G SELECT """\G"""\G`,
+			opts:  []Option{WithVerticalTerminator()},
+			want: []InputStatement{
+				{Statement: `SELECT "1\G2"`, Terminator: `\G`},
+				{Statement: `SELECT '\G'`, Terminator: `\G`},
+				{Statement: `SELECT """\G"""`, Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "vertical terminator not recognized by default",
+			input: `SELECT 1\G SELECT 2;`,
+			want:  []InputStatement{{Statement: `SELECT 1\G SELECT 2`, Terminator: ";"}},
+		},
+		{
+			desc:  "with other options",
+			input: "SELECT 1 -- comment\n\\G",
+			opts:  []Option{WithVerticalTerminator(), WithKeepTerminator(true)},
+			want:  []InputStatement{{Statement: `SELECT 1\G`, Terminator: `\G`}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := SeparateSQL(tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("SeparateSQL() returns error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateSQL_Strict(t *testing.T) {
+	_, err := SeparateSQL(`SELECT 1\G SELECT '2`, WithVerticalTerminator(), WithStrict(true))
+	if diff := cmp.Diff(&UnclosedError{Offset: 18, Delimiter: "'", Kind: WaitingStringLiteral}, err); diff != "" {
+		t.Errorf("difference in error: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_DashCommentRequireSpace(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		require bool
+		input   string
+		want    []string
+	}{
+		{
+			desc:    "comment followed by a space",
+			require: true,
+			input:   "SELECT 1 -- comment;\n; SELECT 2 --\tcomment\n",
+			want:    []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:    "comment at the end of input",
+			require: true,
+			input:   "SELECT 1; SELECT 2 --",
+			want:    []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:    "comment followed by a newline",
+			require: true,
+			input:   "SELECT 1 --\r\n; SELECT 2",
+			want:    []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:    "double minus operators",
+			require: true,
+			input:   "SELECT 5 --3; SELECT x=--y; SELECT a--b",
+			want:    []string{"SELECT 5 --3", "SELECT x=--y", "SELECT a--b"},
+		},
+		{
+			desc:    "single minus is not affected",
+			require: true,
+			input:   "SELECT x=-y; SELECT 5 - -3",
+			want:    []string{"SELECT x=-y", "SELECT 5 - -3"},
+		},
+		{
+			desc:    "other comments are not affected",
+			require: true,
+			input:   "SELECT 1 #comment;\n/*comment;*/; SELECT 2",
+			want:    []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:  "disabled",
+			input: "SELECT 5 --3;\n; SELECT x=-y",
+			want:  []string{"SELECT 5", "SELECT x=-y"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithDashCommentRequireSpace(tt.require)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_LeadingComments(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		preserveComments bool
+		want             []InputStatement
+	}{
+		{
+			desc:  "hash and block comments",
+			input: "# comment 1\n/* comment 2 */ SELECT 1; SELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", LeadingComments: []string{"# comment 1", "/* comment 2 */"}},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+		{
+			desc:             "inner comments are not captured in preserve mode",
+			input:            "-- comment 1\r\nSELECT /* inner */ 1 -- trailing\n;\n/* comment 2 */SELECT 2;",
+			preserveComments: true,
+			want: []InputStatement{
+				{Statement: "SELECT /* inner */ 1 -- trailing", Terminator: ";", LeadingComments: []string{"-- comment 1"}},
+				{Statement: "SELECT 2", Terminator: ";", LeadingComments: []string{"/* comment 2 */"}},
+			},
+		},
+		{
+			desc:  "inner comments are stripped",
+			input: "/* comment */ SELECT /* inner */ 1",
+			want: []InputStatement{
+				{Statement: "SELECT   1", Terminator: "", LeadingComments: []string{"/* comment */"}},
+			},
+		},
+		{
+			desc:  "empty statement",
+			input: "SELECT 1; /* comment */;/* comment */",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "", Terminator: ";", LeadingComments: []string{"/* comment */"}},
+				{Statement: "", Terminator: "", LeadingComments: []string{"/* comment */"}},
+			},
+		},
+		{
+			desc:             "comments after the last terminator",
+			input:            "SELECT 1;\n-- comment 1\n/* comment 2",
+			preserveComments: true,
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "", Terminator: "", LeadingComments: []string{"-- comment 1", "/* comment 2"}},
+			},
+		},
+		{
+			desc:  "comment-like sequences in strings",
+			input: "SELECT '/* not comment */'; SELECT `-- not comment`",
+			want: []InputStatement{
+				{Statement: "SELECT '/* not comment */'", Terminator: ";"},
+				{Statement: "SELECT `-- not comment`", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithLeadingComments(true), WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_TrailingComments(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		preserveComments bool
+		want             []InputStatement
+	}{
+		{
+			desc:  "block comment before terminator",
+			input: "SELECT 1 /* note */ ; SELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", TrailingComments: []string{"/* note */"}},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+		{
+			desc:             "multiple comments in preserve mode",
+			input:            "SELECT /* inner */ 1 -- note 1\n# note 2\r\n;SELECT 2 /* note 3 */",
+			preserveComments: true,
+			want: []InputStatement{
+				{Statement: "SELECT /* inner */ 1", Terminator: ";", TrailingComments: []string{"-- note 1", "# note 2"}},
+				{Statement: "SELECT 2", Terminator: "", TrailingComments: []string{"/* note 3 */"}},
+			},
+		},
+		{
+			desc:  "comments followed by a token are not captured",
+			input: "SELECT 1 /* inner */ + 2 /* note */;",
+			want: []InputStatement{
+				{Statement: "SELECT 1   + 2", Terminator: ";", TrailingComments: []string{"/* note */"}},
+			},
+		},
+		{
+			desc:  "comments in strings are not captured",
+			input: "SELECT '/* not comment */' ; SELECT \"-- not comment\"\n;",
+			want: []InputStatement{
+				{Statement: "SELECT '/* not comment */'", Terminator: ";"},
+				{Statement: `SELECT "-- not comment"`, Terminator: ";"},
+			},
+		},
+		{
+			desc:  "leading comments are not captured",
+			input: "/* leading */ SELECT 1;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithTrailingComments(true), WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_LeadingAndTrailingComments(t *testing.T) {
+	input := "-- leading\nSELECT 1 -- trailing\n;"
+	got, _ := NewSeparator(input, WithLeadingComments(true), WithTrailingComments(true), WithPreserveComments(true)).separate()
+	want := []InputStatement{
+		{Statement: "SELECT 1", Terminator: ";", Start: 11, End: 19, Line: 2, Column: 1, TerminatorOffset: 32, Terminated: true,
+			LeadingComments: []string{"-- leading"}, TrailingComments: []string{"-- trailing"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_CommentReplacement(t *testing.T) {
+	for _, tt := range []struct {
+		desc        string
+		replacement string
+		input       string
+		want        []InputStatement
+	}{
+		{
+			desc:        "empty replacement",
+			replacement: "",
+			input:       "SELECT/* a */1; SELECT a/* */ /* */b -- comment\n+ 1",
+			want: []InputStatement{
+				{Statement: "SELECT1", Terminator: ";", Start: 0, End: 14, Line: 1, Column: 1, TerminatorOffset: 14, Terminated: true},
+				{Statement: "SELECT a b + 1", Terminator: "", Start: 16, End: 51, Line: 1, Column: 17, TerminatorOffset: -1, Index: 1},
+			},
+		},
+		{
+			desc:        "space replacement",
+			replacement: " ",
+			input:       "SELECT/* a */1; SELECT a/* */ /* */b -- comment\n+ 1",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 0, End: 14, Line: 1, Column: 1, TerminatorOffset: 14, Terminated: true},
+				{Statement: "SELECT a   b  + 1", Terminator: "", Start: 16, End: 51, Line: 1, Column: 17, TerminatorOffset: -1, Index: 1},
+			},
+		},
+		{
+			desc:        "other filler",
+			replacement: "\n",
+			input:       "/* leading */SELECT 1 -- comment\n;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 13, End: 21, Line: 1, Column: 14, TerminatorOffset: 33, Terminated: true},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithCommentReplacement(tt.replacement)).separate()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_CommentReplacementPreserveComments(t *testing.T) {
+	input := "SELECT/* a */1;"
+	got, _ := NewSeparator(input, WithCommentReplacement(""), WithPreserveComments(true)).separate()
+	if diff := cmp.Diff([]string{"SELECT/* a */1"}, statements(got)); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_DirectivePrefix(t *testing.T) {
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		preserveComments bool
+		want             []string
+		wantDirectives   []string
+	}{
+		{
+			desc:           "migrate directives",
+			input:          "-- +migrate Up\nCREATE TABLE t (a INT64) PRIMARY KEY (a);\n-- +migrate Down\r\nDROP TABLE t;",
+			want:           []string{"CREATE TABLE t (a INT64) PRIMARY KEY (a)", "DROP TABLE t"},
+			wantDirectives: []string{"-- +migrate Up", "-- +migrate Down"},
+		},
+		{
+			desc:             "directives are stripped in preserve mode",
+			input:            "#!/usr/bin/env spanner-cli\n-- comment\n-- +migrate Up\nSELECT 1;",
+			preserveComments: true,
+			want:             []string{"-- comment\n SELECT 1"},
+			wantDirectives:   []string{"#!/usr/bin/env spanner-cli", "-- +migrate Up"},
+		},
+		{
+			desc:           "not directives",
+			input:          "/* +migrate Up */ SELECT '-- +migrate Up'; --+migrate Up\nSELECT 2",
+			want:           []string{"SELECT '-- +migrate Up'", "SELECT 2"},
+			wantDirectives: nil,
+		},
+		{
+			desc:           "directive at the end of input",
+			input:          "SELECT 1; -- +migrate Down",
+			want:           []string{"SELECT 1"},
+			wantDirectives: []string{"-- +migrate Down"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var directives []string
+			fn := func(line string) {
+				directives = append(directives, line)
+			}
+			got, _ := NewSeparator(tt.input, WithDirectivePrefix("-- +migrate", fn), WithDirectivePrefix("#!", fn),
+				WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantDirectives, directives); diff != "" {
+				t.Errorf("difference in directives: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_AnnotationPrefix(t *testing.T) {
+	type annotation struct{ Name, Rest string }
+	for _, tt := range []struct {
+		desc             string
+		input            string
+		preserveComments bool
+		want             []string
+		wantAnnotations  []annotation
+	}{
+		{
+			desc:            "sqlc annotations",
+			input:           "-- name: GetUser :one\nSELECT * FROM users WHERE id = @id;\n--name:ListUsers :many\r\nSELECT * FROM users;",
+			want:            []string{"SELECT * FROM users WHERE id = @id", "SELECT * FROM users"},
+			wantAnnotations: []annotation{{"GetUser", ":one"}, {"ListUsers", ":many"}},
+		},
+		{
+			desc:             "annotations are stripped in preserve mode",
+			input:            "-- comment\n-- name: GetUser :one\nSELECT 1;",
+			preserveComments: true,
+			want:             []string{"-- comment\n SELECT 1"},
+			wantAnnotations:  []annotation{{"GetUser", ":one"}},
+		},
+		{
+			desc:            "name without rest",
+			input:           "SELECT 1; -- name:  Bare  ",
+			want:            []string{"SELECT 1"},
+			wantAnnotations: []annotation{{"Bare", ""}},
+		},
+		{
+			desc:            "not annotations",
+			input:           "/* name: A */ # name: B\nSELECT '-- name: C' -- names: D\n",
+			want:            []string{"SELECT '-- name: C'"},
+			wantAnnotations: nil,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var annotations []annotation
+			got, _ := NewSeparator(tt.input, WithAnnotationPrefix("name:", func(name, rest string) {
+				annotations = append(annotations, annotation{name, rest})
+			}), WithPreserveComments(tt.preserveComments)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantAnnotations, annotations); diff != "" {
+				t.Errorf("difference in annotations: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_PrimaryTerminator(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		opts       []Option
+		input      string
+		want       []InputStatement
+		wantStatus Status
+	}{
+		{
+			desc:  "slash",
+			opts:  []Option{WithPrimaryTerminator("/")},
+			input: "SELECT 1; SELECT 2 /\n  /\nSELECT ';/' /* / */ / SELECT 3",
+			want: []InputStatement{
+				{Statement: "SELECT 1; SELECT 2", Terminator: "/"},
+				{Statement: "", Terminator: "/"},
+				{Statement: "SELECT ';/'", Terminator: "/"},
+				{Statement: "SELECT 3", Terminator: ""},
+			},
+		},
+		{
+			desc:  "division",
+			opts:  []Option{WithPrimaryTerminator("/")},
+			input: "SELECT 4/2 /",
+			want: []InputStatement{
+				{Statement: "SELECT 4", Terminator: "/"},
+				{Statement: "2", Terminator: "/"},
+			},
+		},
+		{
+			desc:       "unclosed string",
+			opts:       []Option{WithPrimaryTerminator("/")},
+			input:      "SELECT 1 / SELECT '2/",
+			want:       []InputStatement{{Statement: "SELECT 1", Terminator: "/"}, {Statement: "SELECT '2/", Terminator: ""}},
+			wantStatus: Status{WaitingString: "'", WaitingKind: WaitingStringLiteral},
+		},
+		{
+			desc:  "with custom terminators",
+			opts:  []Option{WithPrimaryTerminator("/"), WithTerminators(`\G`)},
+			input: `SELECT 1\G SELECT 2; /`,
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: `\G`}, {Statement: "SELECT 2;", Terminator: "/"}},
+		},
+		{
+			desc:  "slash on its own line",
+			opts:  []Option{WithPrimaryTerminator(""), WithBatchSeparator("/")},
+			input: "BEGIN\n  SELECT 4/2;\nEND;\n/\nSELECT 1;\n/\n",
+			want: []InputStatement{
+				{Statement: "BEGIN\n  SELECT 4/2;\nEND;", Terminator: "/"},
+				{Statement: "SELECT 1;", Terminator: "/"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, status := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStatus, status); diff != "" {
+				t.Errorf("difference in status: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_TerminatorsAroundQuotes(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		terms []string
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "terminator right after strings",
+			terms: []string{`\G`},
+			input: "SELECT 'a'\\GSELECT \"b\"\\GSELECT '''c'''\\GSELECT `d`\\G",
+			want: []InputStatement{
+				{Statement: "SELECT 'a'", Terminator: `\G`},
+				{Statement: `SELECT "b"`, Terminator: `\G`},
+				{Statement: "SELECT '''c'''", Terminator: `\G`},
+				{Statement: "SELECT `d`", Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "terminator in strings",
+			terms: []string{`\G`},
+			input: "SELECT '\\G', \"a\\G\", r'\\G', b'\\G', `\\G`\\G",
+			want: []InputStatement{
+				{Statement: "SELECT '\\G', \"a\\G\", r'\\G', b'\\G', `\\G`", Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "terminator starting with a quote is not matched",
+			terms: []string{`"X`},
+			input: `SELECT "X"X; SELECT 1"X`,
+			want: []InputStatement{
+				{Statement: `SELECT "X"X`, Terminator: ";"},
+				{Statement: `SELECT 1"X`, Terminator: ""},
+			},
+		},
+		{
+			desc:  "terminator ending with a quote",
+			terms: []string{`X"`},
+			input: `SELECT "X"X" SELECT 2`,
+			want: []InputStatement{
+				{Statement: `SELECT "X"`, Terminator: `X"`},
+				{Statement: "SELECT 2", Terminator: ""},
+			},
+		},
+		{
+			desc:  "terminator starting with a string prefix",
+			terms: []string{"bye", "R!"},
+			input: "SELECT 1 bye SELECT 2 R! SELECT b'bye', r'R!'bye",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: "bye"},
+				{Statement: "SELECT 2", Terminator: "R!"},
+				{Statement: "SELECT b'bye', r'R!'", Terminator: "bye"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := SeparateInput(tt.input, tt.terms...)
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_Reset(t *testing.T) {
+	s := NewSeparator("", WithTerminators(`\G`), WithDelimiterCommand(true), WithStrict(true))
+	for _, tt := range []struct {
+		input      string
+		want       []InputStatement
+		wantErr    error
+		wantStatus Status
+	}{
+		{
+			input: "SELECT 1; SELECT 2\\G",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 0, End: 8, Line: 1, Column: 1, TerminatorOffset: 8, Terminated: true},
+				{Statement: "SELECT 2", Terminator: `\G`, Start: 10, End: 18, Line: 1, Column: 11, TerminatorOffset: 18, Terminated: true, Index: 1},
+			},
+		},
+		{
+			input:      "SELECT 1; SELECT '2",
+			want:       []InputStatement{{Statement: "SELECT 1", Terminator: ";", Start: 0, End: 8, Line: 1, Column: 1, TerminatorOffset: 8, Terminated: true}},
+			wantErr:    &UnclosedError{Offset: 17, Delimiter: "'", Kind: WaitingStringLiteral},
+			wantStatus: Status{WaitingString: "'", WaitingKind: WaitingStringLiteral},
+		},
+		{
+			input: "DELIMITER //\nSELECT 1; SELECT 2//",
+			want: []InputStatement{
+				{Statement: "SELECT 1; SELECT 2", Terminator: "//", Start: 13, End: 31, Line: 2, Column: 1, TerminatorOffset: 31, Terminated: true},
+			},
+		},
+		{
+			input: "\uFEFFSELECT 1;\nSELECT 2",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 3, End: 11, Line: 1, Column: 1, TerminatorOffset: 11, Terminated: true},
+				{Statement: "SELECT 2", Terminator: "", Start: 13, End: 21, Line: 2, Column: 1, TerminatorOffset: -1, Index: 1},
+			},
+		},
+	} {
+		s.Reset(tt.input)
+		got, err := s.ReadAll()
+		if diff := cmp.Diff(tt.wantErr, err); diff != "" {
+			t.Errorf("%q: difference in error: (-want +got):\n%s", tt.input, diff)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%q: difference in statements: (-want +got):\n%s", tt.input, diff)
+		}
+		if diff := cmp.Diff(tt.wantStatus, s.Status()); diff != "" {
+			t.Errorf("%q: difference in status: (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestSeparator_TrimCutset(t *testing.T) {
+	const input = "\u00A0SELECT 1\u00A0;\n SELECT 2\u00A0\n"
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		want []InputStatement
+	}{
+		{
+			desc: "default",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 2, End: 10, Line: 1, Column: 2, TerminatorOffset: 12, Terminated: true},
+				{Statement: "SELECT 2", Terminator: "", Start: 15, End: 23, Line: 2, Column: 2, TerminatorOffset: -1, Index: 1},
+			},
+		},
+		{
+			desc: "ASCII whitespaces",
+			opts: []Option{WithTrimCutset(" \t\r\n")},
+			want: []InputStatement{
+				{Statement: "\u00A0SELECT 1\u00A0", Terminator: ";", Start: 0, End: 12, Line: 1, Column: 1, TerminatorOffset: 12, Terminated: true},
+				{Statement: "SELECT 2\u00A0", Terminator: "", Start: 15, End: 25, Line: 2, Column: 2, TerminatorOffset: -1, Index: 1},
+			},
+		},
+		{
+			desc: "non-breaking space only",
+			opts: []Option{WithTrimCutset("\u00A0")},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Start: 2, End: 10, Line: 1, Column: 2, TerminatorOffset: 12, Terminated: true},
+				{Statement: "\n SELECT 2\u00A0\n", Terminator: "", Start: 13, End: 26, Line: 1, Column: 12, TerminatorOffset: -1, Index: 1},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewSeparator(input, tt.opts...).ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll() returns error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		cutset string
+		want   []string
+	}{
+		{" \t\r\n", []string{"SELECT 1", "\u00A0", "\u3000"}},
+		{" \t\r\n\u00A0\u3000", []string{"SELECT 1"}},
+	} {
+		got, _ := NewSeparator("SELECT 1; \u00A0 ;\u3000;\n;", WithTrimCutset(tt.cutset), WithSkipEmpty(true)).separate()
+		if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+			t.Errorf("WithTrimCutset(%q) and WithSkipEmpty(true): difference in statements: (-want +got):\n%s", tt.cutset, diff)
+		}
+	}
+}
+
+func TestSeparator_TypedLiterals(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  []InputStatement
+	}{
+		{
+			input: "SELECT DATE '2020-01-01';",
+			want: []InputStatement{
+				{Statement: "SELECT DATE '2020-01-01'", Terminator: ";"},
+			},
+		},
+		{
+			input: `SELECT TIMESTAMP "2020-01-01 00:00:00;", NUMERIC '1;'; SELECT JSON '{"a": ";"}'`,
+			want: []InputStatement{
+				{Statement: `SELECT TIMESTAMP "2020-01-01 00:00:00;", NUMERIC '1;'`, Terminator: ";"},
+				{Statement: `SELECT JSON '{"a": ";"}'`, Terminator: ""},
+			},
+		},
+		{
+			input: "SELECT DATE '2020-01-01",
+			want: []InputStatement{
+				{Statement: "SELECT DATE '2020-01-01", Terminator: ""},
+			},
+		},
+	} {
+		for _, typed := range []bool{false, true} {
+			got, _ := NewSeparator(tt.input, WithTypedLiterals(typed)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("%q, typed literals %v: difference in statements: (-want +got):\n%s", tt.input, typed, diff)
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		terms []string
+		want  error
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+		},
+		{
+			desc:  "terminated statements",
+			input: "SELECT 1;\nSELECT 'a;' /* ; */; -- comment\n",
+		},
+		{
+			desc:  "custom terminators",
+			input: `SELECT 1\G SELECT 2;`,
+			terms: []string{`\G`},
+		},
+		{
+			desc:  "missing final terminator",
+			input: "SELECT 1; SELECT 2 -- comment",
+			want:  &MissingTerminatorError{Offset: 18},
+		},
+		{
+			desc:  "unclosed string",
+			input: "SELECT 1; SELECT 'a;",
+			want:  &UnclosedError{Offset: 17, Delimiter: "'", Kind: WaitingStringLiteral},
+		},
+		{
+			desc:  "unclosed comment",
+			input: "SELECT 1; /* comment;",
+			want:  &UnclosedError{Offset: 10, Delimiter: "*/", Kind: WaitingComment},
+		},
+		{
+			desc:  "unclosed quoted identifier",
+			input: "SELECT 1 FROM `t;",
+			want:  &UnclosedError{Offset: 14, Delimiter: "`", Kind: WaitingQuotedIdentifier},
+		},
+		{
+			desc:  "invalid escape sequence",
+			input: `SELECT '\d';`,
+			want:  &InvalidEscapeError{Offset: 8, Sequence: `\d`},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, Validate(tt.input, tt.terms...)); diff != "" {
+				t.Errorf("difference in error: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMissingTerminatorError_Error(t *testing.T) {
+	err := &MissingTerminatorError{Offset: 18}
+	if got, want := err.Error(), "missing terminator at offset 18"; got != want {
+		t.Errorf("Error() = %q, but want = %q", got, want)
+	}
+}
+
+func TestSeparator_DropTrailingCommentOnly(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []InputStatement
+	}{
+		{
+			desc:  "trailing whitespaces",
+			input: "SELECT 1;\n   \n",
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+		},
+		{
+			desc:  "trailing comment stripped",
+			input: "SELECT 1; -- bye",
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+		},
+		{
+			desc:  "trailing comment preserved",
+			input: "SELECT 1; -- bye",
+			opts:  []Option{WithPreserveComments(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "-- bye", Terminator: ""},
+			},
+		},
+		{
+			desc:  "trailing comment dropped",
+			input: "SELECT 1; -- bye\n/* bye */\n",
+			opts:  []Option{WithPreserveComments(true), WithDropTrailingCommentOnly(true)},
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+		},
+		{
+			desc:  "comment-only input dropped",
+			input: "-- bye",
+			opts:  []Option{WithPreserveComments(true), WithDropTrailingCommentOnly(true)},
+			want:  nil,
+		},
+		{
+			desc:  "trailing whitespaces dropped without trimming",
+			input: "SELECT 1; -- bye\n",
+			opts:  []Option{WithTrimSpace(false), WithDropTrailingCommentOnly(true)},
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+		},
+		{
+			desc:  "captured leading comments dropped",
+			input: "SELECT 1; -- bye",
+			opts:  []Option{WithLeadingComments(true), WithDropTrailingCommentOnly(true)},
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";"}},
+		},
+		{
+			desc:  "unterminated statement with comment kept",
+			input: "SELECT 1; SELECT 2 -- bye",
+			opts:  []Option{WithPreserveComments(true), WithDropTrailingCommentOnly(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2 -- bye", Terminator: ""},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_CollectPlaceholders(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  [][]Placeholder
+	}{
+		{
+			desc:  "named parameters",
+			input: "SELECT @a, @b; SELECT @a_1",
+			want: [][]Placeholder{
+				{{Text: "@a", Offset: 7}, {Text: "@b", Offset: 11}},
+				{{Text: "@a_1", Offset: 22}},
+			},
+		},
+		{
+			desc:  "positional parameters",
+			input: "SELECT ?, ?+1;",
+			want: [][]Placeholder{
+				{{Text: "?", Offset: 7}, {Text: "?", Offset: 10}},
+			},
+		},
+		{
+			desc:  "ignored in strings, comments, and quoted identifiers",
+			input: "SELECT '?', \"@a\", `@b` /* @c ? */ -- @d ?\n, @e;",
+			want: [][]Placeholder{
+				{{Text: "@e", Offset: 44}},
+			},
+		},
+		{
+			desc:  "system variables and bare at signs",
+			input: "SET @@optimizer_version = 1; SELECT @ 1;",
+			want:  [][]Placeholder{nil, nil},
+		},
+		{
+			desc:  "quoted parameter names",
+			input: "SELECT @`my param`, @`a;``b`; SELECT @`unclosed;",
+			want: [][]Placeholder{
+				{{Text: "@`my param`", Offset: 7}, {Text: "@`a;``b`", Offset: 20}},
+				nil,
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			stmts, _ := NewSeparator(tt.input, WithCollectPlaceholders(true)).separate()
+			var got [][]Placeholder
+			for _, stmt := range stmts {
+				got = append(got, stmt.Placeholders)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in placeholders: (-want +got):\n%s", diff)
+			}
+
+			// placeholders don't change statements
+			if diff := cmp.Diff(SeparateInput(tt.input), stmts, cmpopts.IgnoreFields(InputStatement{}, "Placeholders")); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	stmts, _ := NewSeparator("SELECT @a;").separate()
+	if got := stmts[0].Placeholders; got != nil {
+		t.Errorf("Placeholders without WithCollectPlaceholders = %v, but want = nil", got)
+	}
+}
+
+func TestSeparator_LineContinuation(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []InputStatement
+	}{
+		{
+			desc:  "backslash and newline",
+			input: "SELECT 1, \\\n2;\nSELECT \\\r\n3",
+			opts:  []Option{WithLineContinuation(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1, 2", Terminator: ";"},
+				{Statement: "SELECT 3", Terminator: ""},
+			},
+		},
+		{
+			desc:  "vertical terminator",
+			input: "SELECT 1\\G\nSELECT \\\n2\\G",
+			opts:  []Option{WithLineContinuation(true), WithVerticalTerminator()},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\G`},
+				{Statement: "SELECT 2", Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "backslash not followed by newline",
+			input: "SELECT 1 \\ 2;",
+			opts:  []Option{WithLineContinuation(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1 \\ 2", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "backslash in string",
+			input: "SELECT 'a\\\nb';",
+			opts:  []Option{WithLineContinuation(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 'a\\\nb'", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "disabled",
+			input: "SELECT 1, \\\n2;",
+			want: []InputStatement{
+				{Statement: "SELECT 1, \\\n2", Terminator: ";"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_HintAwareness(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []string
+	}{
+		{
+			desc:  "statement hint",
+			input: "@{OPTIMIZER_VERSION=1} SELECT 1; SELECT 2",
+			want:  []string{"@{OPTIMIZER_VERSION=1} SELECT 1", "SELECT 2"},
+		},
+		{
+			desc:  "terminator in hint",
+			input: "@{a=1;b=2} SELECT 1 FROM t@{FORCE_INDEX=idx};",
+			want:  []string{"@{a=1;b=2} SELECT 1 FROM t@{FORCE_INDEX=idx}"},
+		},
+		{
+			desc:  "nested braces and strings",
+			input: "@{a={b;}, c='};'} SELECT 1;",
+			want:  []string{"@{a={b;}, c='};'} SELECT 1"},
+		},
+		{
+			desc:  "unclosed hint",
+			input: "@{a=1; SELECT 1",
+			want:  []string{"@{a=1; SELECT 1"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithHintAwareness(true)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	got, _ := NewSeparator("@{a=1;b=2} SELECT 1").separate()
+	if diff := cmp.Diff([]string{"@{a=1", "b=2} SELECT 1"}, statements(got)); diff != "" {
+		t.Errorf("difference in statements without WithHintAwareness: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_StatementTransform(t *testing.T) {
+	upper := WithStatementTransform(func(stmt InputStatement) InputStatement {
+		stmt.Statement = strings.ToUpper(stmt.Statement)
+		return stmt
+	})
+	got, err := NewSeparator("  select 1 ;;\nselect 'a'\\G", upper, WithSkipEmpty(true), WithVerticalTerminator()).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() returns error: %v", err)
+	}
+	want := []InputStatement{
+		{Statement: "SELECT 1", Terminator: ";", Start: 2, End: 10, Line: 1, Column: 3, TerminatorOffset: 11, Terminated: true},
+		{Statement: "SELECT 'A'", Terminator: `\G`, Start: 14, End: 24, Line: 2, Column: 1, TerminatorOffset: 24, Terminated: true, Index: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparate_NoStatements(t *testing.T) {
+	for _, input := range []string{"", "   \n\t", "-- comment\n/* comment */ # comment", "\uFEFF"} {
+		if got := SeparateInput(input); got != nil {
+			t.Errorf("SeparateInput(%q) = %#v, but want = nil", input, got)
+		}
+		if got := SeparateInputString(input); got != nil {
+			t.Errorf("SeparateInputString(%q) = %#v, but want = nil", input, got)
+		}
+		if got := SeparateInputRanges(input); got != nil {
+			t.Errorf("SeparateInputRanges(%q) = %#v, but want = nil", input, got)
+		}
+		if got := SeparateWithTerminatorTokens(input); got != nil {
+			t.Errorf("SeparateWithTerminatorTokens(%q) = %#v, but want = nil", input, got)
+		}
+		if got, err := SeparateInputStrict(input); got != nil || err != nil {
+			t.Errorf("SeparateInputStrict(%q) = %#v, %v, but want = nil, nil", input, got, err)
+		}
+		if got, err := SeparateReader(strings.NewReader(input)); got != nil || err != nil {
+			t.Errorf("SeparateReader(%q) = %#v, %v, but want = nil, nil", input, got, err)
+		}
+	}
+
+	// comments are returned as statements if they are preserved, so only blank input is checked.
+	for _, input := range []string{"", "   \n\t"} {
+		if got := SeparateInputPreserveComments(input); got != nil {
+			t.Errorf("SeparateInputPreserveComments(%q) = %#v, but want = nil", input, got)
+		}
+	}
+}
+
+func TestSeparator_BracketAwareTerminators(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []string
+	}{
+		{
+			desc:  "custom terminator in square brackets",
+			input: `SELECT [1, 2 \G 3]\GSELECT 4\G`,
+			opts:  []Option{WithBracketAwareTerminators(true), WithVerticalTerminator()},
+			want:  []string{`SELECT [1, 2 \G 3]`, "SELECT 4"},
+		},
+		{
+			desc:  "nested brackets",
+			input: "SELECT ARRAY(SELECT [1; 2]); SELECT (1)",
+			opts:  []Option{WithBracketAwareTerminators(true)},
+			want:  []string{"SELECT ARRAY(SELECT [1; 2])", "SELECT (1)"},
+		},
+		{
+			desc:  "brackets in strings, comments, and quoted identifiers",
+			input: "SELECT '(', `[` /* ( */; SELECT ')'; SELECT 1",
+			opts:  []Option{WithBracketAwareTerminators(true)},
+			want:  []string{"SELECT '(', `[`", "SELECT ')'", "SELECT 1"},
+		},
+		{
+			desc:  "unmatched closing bracket",
+			input: "SELECT 1); SELECT [2;",
+			opts:  []Option{WithBracketAwareTerminators(true)},
+			want:  []string{"SELECT 1)", "SELECT [2;"},
+		},
+		{
+			desc:  "disabled",
+			input: `SELECT [1, 2 \G 3]\G`,
+			opts:  []Option{WithVerticalTerminator()},
+			want:  []string{"SELECT [1, 2", "3]"},
+		},
+		{
+			desc:  "semicolons in balanced parentheses",
+			input: "CREATE TABLE t (a INT; b INT; c STRUCT<d INT>, PRIMARY KEY ((a); (b)));\nSELECT (1; 2)\\G",
+			opts:  []Option{WithParenAwareTerminator(true), WithVerticalTerminator()},
+			want:  []string{"CREATE TABLE t (a INT; b INT; c STRUCT<d INT>, PRIMARY KEY ((a); (b)))", "SELECT (1; 2)"},
+		},
+		{
+			desc:  "square brackets are not counted",
+			input: "SELECT [1; 2]; SELECT ([3; 4])",
+			opts:  []Option{WithParenAwareTerminator(true)},
+			want:  []string{"SELECT [1", "2]", "SELECT ([3; 4])"},
+		},
+		{
+			desc:  "unbalanced parentheses",
+			input: "SELECT 1)); SELECT (2; SELECT (3);",
+			opts:  []Option{WithParenAwareTerminator(true)},
+			want:  []string{"SELECT 1))", "SELECT (2; SELECT (3);"},
+		},
+		{
+			desc:  "parentheses in strings and comments",
+			input: "SELECT '(', \"(\" -- (\n; SELECT /* ) */ (1; 2)",
+			opts:  []Option{WithParenAwareTerminator(true)},
+			want:  []string{"SELECT '(', \"(\"", "SELECT   (1; 2)"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_CanonicalTerminator(t *testing.T) {
+	canonical := WithCanonicalTerminator(map[string]string{`\g`: `\G`})
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		want []InputStatement
+	}{
+		{
+			desc: "canonical terminators",
+			opts: []Option{WithTerminators(`\G`, `\g`), canonical},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\G`},
+				{Statement: "SELECT 2", Terminator: `\G`},
+				{Statement: "SELECT 3", Terminator: ";"},
+			},
+		},
+		{
+			desc: "statements untouched",
+			opts: []Option{WithTerminators(`\G`, `\g`), canonical, WithKeepTerminator(true)},
+			want: []InputStatement{
+				{Statement: `SELECT 1\g`, Terminator: `\G`},
+				{Statement: `SELECT 2\G`, Terminator: `\G`},
+				{Statement: "SELECT 3;", Terminator: ";"},
+			},
+		},
+		{
+			desc: "without canonical terminators",
+			opts: []Option{WithTerminators(`\G`, `\g`)},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: `\g`},
+				{Statement: "SELECT 2", Terminator: `\G`},
+				{Statement: "SELECT 3", Terminator: ";"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(`SELECT 1\g SELECT 2\G SELECT 3;`, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	got, err := SeparateSQL(`SELECT 1\g \g`, WithTerminators(`\g`), canonical, WithKeepTerminator(true), WithSkipEmpty(true))
+	if err != nil {
+		t.Fatalf("SeparateSQL() returns error: %v", err)
+	}
+	if diff := cmp.Diff([]string{`SELECT 1\g`}, statements(got)); diff != "" {
+		t.Errorf("difference in statements with WithSkipEmpty: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_ValidateUTF8(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{
+			desc:  "valid",
+			input: "SELECT 'テスト'; SELECT 2",
+			want:  []string{"SELECT 'テスト'", "SELECT 2"},
+		},
+		{
+			desc:    "invalid byte in string",
+			input:   "SELECT 1; SELECT 'caf\xe9'; SELECT 3",
+			want:    []string{"SELECT 1"},
+			wantErr: &InvalidUTF8Error{Offset: 21},
+		},
+		{
+			desc:    "invalid byte at the end of input",
+			input:   "SELECT 1; -- \xff",
+			want:    []string{"SELECT 1"},
+			wantErr: &InvalidUTF8Error{Offset: 13},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewSeparator(tt.input, WithValidateUTF8(true)).ReadAll()
+			if diff := cmp.Diff(tt.wantErr, err); diff != "" {
+				t.Errorf("difference in error: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// invalid bytes are kept as is by default
+	if diff := cmp.Diff([]string{"SELECT 'caf\xe9'"}, SeparateInputString("SELECT 'caf\xe9';")); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestInvalidUTF8Error_Error(t *testing.T) {
+	err := &InvalidUTF8Error{Offset: 21}
+	if got, want := err.Error(), "invalid UTF-8 byte at offset 21"; got != want {
+		t.Errorf("Error() = %q, but want = %q", got, want)
+	}
+}
+
+func TestSeparator_Index(t *testing.T) {
+	const input = "SELECT 1;;\nSELECT 2\\G -- comment\n;SELECT 3"
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		want []string
+	}{
+		{
+			desc: "empty statements counted",
+			want: []string{"SELECT 1", "", "SELECT 2", "", "SELECT 3"},
+		},
+		{
+			desc: "empty statements skipped",
+			opts: []Option{WithSkipEmpty(true)},
+			want: []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewSeparator(input, append(tt.opts, WithVerticalTerminator())...).ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll() returns error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			for i, stmt := range got {
+				if stmt.Index != i {
+					t.Errorf("Index of %q = %d, but want = %d", stmt.Statement, stmt.Index, i)
+				}
+			}
+		})
+	}
+
+	var got []int
+	err := SeparateReaderFunc(iotest.OneByteReader(strings.NewReader(input)), func(stmt InputStatement) error {
+		got = append(got, stmt.Index)
+		return nil
+	}, `\G`)
+	if err != nil {
+		t.Fatalf("SeparateReaderFunc() returns error: %v", err)
+	}
+	if diff := cmp.Diff([]int{0, 1, 2, 3, 4}, got); diff != "" {
+		t.Errorf("difference in indices of SeparateReaderFunc: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_RawAndBytesStrings(t *testing.T) {
+	const input = `SELECT r"\"; SELECT 2;`
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		want []string
+	}{
+		{
+			desc: "raw strings",
+			want: []string{`SELECT r"\"`, "SELECT 2"},
+		},
+		{
+			desc: "raw strings disabled",
+			opts: []Option{WithRawStrings(false)},
+			want: []string{`SELECT r"\"; SELECT 2;`},
+		},
+		{
+			desc: "bytes strings disabled",
+			opts: []Option{WithBytesStrings(false)},
+			want: []string{`SELECT r"\"`, "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		want []Token
+	}{
+		{
+			desc: "tokens with prefixes recognized",
+			want: []Token{
+				{Kind: TokenRawString, Text: `r"x"`, Start: 0, End: 4},
+				{Kind: TokenOther, Text: " ", Start: 4, End: 5},
+				{Kind: TokenBytesString, Text: `b"y"`, Start: 5, End: 9},
+				{Kind: TokenOther, Text: " ", Start: 9, End: 10},
+				{Kind: TokenBytesString, Text: `rb"z"`, Start: 10, End: 15},
+			},
+		},
+		{
+			desc: "tokens with raw strings disabled",
+			opts: []Option{WithRawStrings(false)},
+			want: []Token{
+				{Kind: TokenOther, Text: "r", Start: 0, End: 1},
+				{Kind: TokenString, Text: `"x"`, Start: 1, End: 4},
+				{Kind: TokenOther, Text: " ", Start: 4, End: 5},
+				{Kind: TokenBytesString, Text: `b"y"`, Start: 5, End: 9},
+				{Kind: TokenOther, Text: " r", Start: 9, End: 11},
+				{Kind: TokenBytesString, Text: `b"z"`, Start: 11, End: 15},
+			},
+		},
+		{
+			desc: "tokens with bytes strings disabled",
+			opts: []Option{WithBytesStrings(false)},
+			want: []Token{
+				{Kind: TokenRawString, Text: `r"x"`, Start: 0, End: 4},
+				{Kind: TokenOther, Text: " b", Start: 4, End: 6},
+				{Kind: TokenString, Text: `"y"`, Start: 6, End: 9},
+				{Kind: TokenOther, Text: " rb", Start: 9, End: 12},
+				{Kind: TokenString, Text: `"z"`, Start: 12, End: 15},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, Tokenize(`r"x" b"y" rb"z"`, tt.opts...)); diff != "" {
+				t.Errorf("difference in tokens: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparateInput_TruncatedInput(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  []string
+	}{
+		{"r", []string{"r"}},
+		{"R'", []string{"R'"}},
+		{"b\"", []string{"b\""}},
+		{"rb", []string{"rb"}},
+		{"SELECT 1; SELECT rb", []string{"SELECT 1", "SELECT rb"}},
+		{"SELECT Rb; SELECT Rb_col'a;'", []string{"SELECT Rb", "SELECT Rb_col'a;'"}},
+		{"BR'", []string{"BR'"}},
+		{"rb'''", []string{"rb'''"}},
+		{"'", []string{"'"}},
+		{"''", []string{"''"}},
+		{`"""`, []string{`"""`}},
+		{`"""""`, []string{`"""""`}},
+		{"`", []string{"`"}},
+		{"'\\", []string{"'\\"}},
+		{"/*", nil},
+		{"/", []string{"/"}},
+		{"-", []string{"-"}},
+		{"\\", []string{"\\"}},
+	} {
+		if diff := cmp.Diff(tt.want, SeparateInputString(tt.input, `\G`)); diff != "" {
+			t.Errorf("%q: difference in statements: (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  bool
+	}{
+		{"", false},
+		{"  \n", false},
+		{"-- comment", false},
+		{"SELECT 1", false},
+		{"SELECT 1;", true},
+		{"SELECT 1; \n-- comment\n/* comment */ ", true},
+		{"SELECT 1\\G", true},
+		{"SELECT 1; SELECT 2", false},
+		{"SELECT 1 -- ;", false},
+		{"SELECT 1 /* ; */", false},
+		{"SELECT 1; /* comment", false},
+		{"SELECT 'a;", false},
+		{"SELECT '''a;\nb';", false},
+		{"SELECT '''a;\nb''';", true},
+		{"SELECT `a;", false},
+		{";", true},
+	} {
+		if got := IsComplete(tt.input, `\G`); got != tt.want {
+			t.Errorf("IsComplete(%q) = %v, but want = %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSeparator_PreserveCommentIndent(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []string
+	}{
+		{
+			desc:  "leading comment",
+			input: "   -- c\nSELECT 1;\n\n\t/* d */ SELECT 2  \n;  SELECT 3;",
+			opts:  []Option{WithPreserveComments(true), WithPreserveCommentIndent(true)},
+			want:  []string{"   -- c\nSELECT 1", "\t/* d */ SELECT 2", "SELECT 3"},
+		},
+		{
+			desc:  "comment-only statement",
+			input: "SELECT 1;\n  -- bye\n",
+			opts:  []Option{WithPreserveComments(true), WithPreserveCommentIndent(true)},
+			want:  []string{"SELECT 1", "  -- bye"},
+		},
+		{
+			desc:  "disabled",
+			input: "   -- c\nSELECT 1;",
+			opts:  []Option{WithPreserveComments(true)},
+			want:  []string{"-- c\nSELECT 1"},
+		},
+		{
+			desc:  "comments stripped",
+			input: "   -- c\nSELECT 1;",
+			opts:  []Option{WithPreserveCommentIndent(true)},
+			want:  []string{"SELECT 1"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_BatchSeparatorWithSemicolons(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []InputStatement
+	}{
+		{
+			desc:  "batch boundary after terminated statements",
+			input: "CREATE TABLE t (a INT64) PRIMARY KEY (a);\nCREATE INDEX i ON t (a);\nGO\nSELECT 1;\nSELECT 2;\nGO\n",
+			want: []InputStatement{
+				{Statement: "CREATE TABLE t (a INT64) PRIMARY KEY (a)", Terminator: ";"},
+				{Statement: "CREATE INDEX i ON t (a)", Terminator: ";"},
+				{Statement: "", Terminator: "GO"},
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ";"},
+				{Statement: "", Terminator: "GO"},
+			},
+		},
+		{
+			desc:  "interleaved terminators",
+			input: "SELECT 1;\nSELECT 2\nGO\nSELECT 3\n-- comment\nGO\nSELECT 4;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: "GO"},
+				{Statement: "SELECT 3", Terminator: "GO"},
+				{Statement: "SELECT 4", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "not on its own line",
+			input: "SELECT 1; GO\nSELECT 2;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "GO\nSELECT 2", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "empty batch boundaries skipped",
+			input: "SELECT 1;\nGO\nSELECT 2;",
+			opts:  []Option{WithSkipEmpty(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";"},
+				{Statement: "SELECT 2", Terminator: ";"},
+			},
+		},
+		{
+			desc:  "kept batch separators",
+			input: "SELECT 1\nGO\nSELECT 2;\nGO\nSELECT 3 -- comment\n  GO",
+			opts:  []Option{WithKeepTerminator(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1\nGO", Terminator: "GO"},
+				{Statement: "SELECT 2;", Terminator: ";"},
+				{Statement: "GO", Terminator: "GO"},
+				{Statement: "SELECT 3\nGO", Terminator: "GO"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, append(tt.opts, WithBatchSeparator("GO"))...).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_Source(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []string
+	}{
+		{
+			desc:  "statements with comments",
+			input: "\uFEFF-- comment\nSELECT 1 /* ; */;\n  SELECT 2\\G SELECT 3 -- bye\n",
+			want:  []string{"-- comment\nSELECT 1 /* ; */;", "\n  SELECT 2\\G", " SELECT 3 -- bye\n"},
+		},
+		{
+			desc:  "empty statements",
+			input: "SELECT 1;; ",
+			want:  []string{"SELECT 1;", ";"},
+		},
+		{
+			desc:  "stripped comment after the last terminator",
+			input: "SELECT 1; -- bye\n",
+			want:  []string{"SELECT 1;"},
+		},
+		{
+			desc:  "preserved comment after the last terminator",
+			input: "SELECT 1; -- bye\n",
+			opts:  []Option{WithPreserveComments(true)},
+			want:  []string{"SELECT 1;", " -- bye\n"},
+		},
+		{
+			desc:  "delimiter command",
+			input: "DELIMITER //\nSELECT 1; SELECT 2//\nDELIMITER ;\nSELECT 3;",
+			opts:  []Option{WithDelimiterCommand(true)},
+			want:  []string{"DELIMITER //\nSELECT 1; SELECT 2//", "\nDELIMITER ;\nSELECT 3;"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewSeparator(tt.input, append(tt.opts, WithSource(true), WithVerticalTerminator())...).ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll() returns error: %v", err)
+			}
+			var sources []string
+			for _, stmt := range got {
+				sources = append(sources, stmt.Source)
+				if stmt.Terminated {
+					if end := stmt.TerminatorOffset + len(stmt.Terminator); !strings.HasSuffix(tt.input[:end], stmt.Source) {
+						t.Errorf("Source %q is not the input slice ending at %d", stmt.Source, end)
+					}
+				}
+			}
+			if diff := cmp.Diff(tt.want, sources); diff != "" {
+				t.Errorf("difference in sources: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	got, _ := NewSeparator("SELECT 1;").ReadAll()
+	if got[0].Source != "" {
+		t.Errorf("Source without WithSource = %q, but want = \"\"", got[0].Source)
+	}
+}
+
+func TestSeparateInput_OnlyTerminators(t *testing.T) {
+	empty := func(terms ...string) []InputStatement {
+		var stmts []InputStatement
+		for _, term := range terms {
+			stmts = append(stmts, InputStatement{Statement: "", Terminator: term})
+		}
+		return stmts
+	}
+	for _, tt := range []struct {
+		input string
+		want  []InputStatement
+	}{
+		{";", empty(";")},
+		{`\G`, empty(`\G`)},
+		{";;;", empty(";", ";", ";")},
+		{" ; \n\\G\t;", empty(";", `\G`, ";")},
+		{"; /* comment */ ;", empty(";", ";")},
+	} {
+		if diff := cmp.Diff(tt.want, SeparateInput(tt.input, `\G`), ignorePositions); diff != "" {
+			t.Errorf("SeparateInput(%q): difference in statements: (-want +got):\n%s", tt.input, diff)
+		}
+		if diff := cmp.Diff(len(tt.want), len(SeparateInputPreserveComments(tt.input, `\G`))); diff != "" {
+			t.Errorf("SeparateInputPreserveComments(%q): difference in the number of statements: (-want +got):\n%s", tt.input, diff)
+		}
+		if got, _ := NewSeparator(tt.input, WithTerminators(`\G`), WithSkipEmpty(true)).separate(); got != nil {
+			t.Errorf("WithSkipEmpty(true): separate(%q) = %#v, but want = nil", tt.input, got)
+		}
+	}
+
+	got := SeparateInputPreserveComments("; /* comment */ ;")
+	want := []InputStatement{{Statement: "", Terminator: ";"}, {Statement: "/* comment */", Terminator: ";"}}
+	if diff := cmp.Diff(want, got, ignorePositions); diff != "" {
+		t.Errorf("difference in preserved statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_WarnUnknownBackslash(t *testing.T) {
+	type warning struct {
+		Seq    string
+		Offset int
+	}
+	var got []warning
+	stmts, _ := NewSeparator("SELECT 1\\q SELECT 2\\G SELECT '\\n', `\\x` /* \\g */ \\\\ \\1 \\g", WithVerticalTerminator(),
+		WithWarnUnknownBackslash(func(seq string, offset int) {
+			got = append(got, warning{seq, offset})
+		})).separate()
+	want := []warning{{`\q`, 8}, {`\g`, 55}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in warnings: (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{`SELECT 1\q SELECT 2`, "SELECT '\\n', `\\x`   \\\\ \\1 \\g"}, statements(stmts)); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_InitialState(t *testing.T) {
+	for _, tt := range []struct {
+		desc   string
+		first  string
+		second string
+		opts   []Option
+		want   []string
+	}{
+		{
+			desc:   "string literal",
+			first:  "SELECT 1; SELECT 'a;",
+			second: "b\\';c'; SELECT 2;",
+			want:   []string{"SELECT 1", "SELECT 'a;b\\';c'", "SELECT 2"},
+		},
+		{
+			desc:   "triple-quoted string literal",
+			first:  `SELECT """a;"`,
+			second: `";"""; SELECT 2`,
+			want:   []string{`SELECT """a;"";"""`, "SELECT 2"},
+		},
+		{
+			desc:   "quoted identifier",
+			first:  "SELECT * FROM `a;",
+			second: "b``;c`;",
+			want:   []string{"SELECT * FROM `a;b``;c`"},
+		},
+		{
+			desc:   "comment",
+			first:  "SELECT 1 /* a;",
+			second: "b; */; SELECT 2;",
+			opts:   []Option{WithPreserveComments(true)},
+			want:   []string{"SELECT 1 /* a;b; */", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			first, status := NewSeparator(tt.first, tt.opts...).separate()
+			if status.WaitingString == "" {
+				t.Fatalf("status of the first part = %#v, but want waiting", status)
+			}
+			second, status := NewSeparator(tt.second, append(tt.opts, WithInitialState(status))...).separate()
+			if status.WaitingString != "" {
+				t.Errorf("status of the second part = %#v, but want not waiting", status)
+			}
+
+			got := statements(first[:len(first)-1])
+			got = append(got, first[len(first)-1].Statement+second[0].Statement)
+			got = append(got, statements(second[1:])...)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_CommentAdjacentToToken(t *testing.T) {
+	for _, tt := range []struct {
+		input         string
+		want          []string
+		wantPreserved []string
+	}{
+		{"SELECT 'x'/* c */;", []string{"SELECT 'x'"}, []string{"SELECT 'x'/* c */"}},
+		{"SELECT `id`-- c\n;", []string{"SELECT `id`"}, []string{"SELECT `id`-- c"}},
+		{"SELECT r\"x\"#c\n;", []string{`SELECT r"x"`}, []string{`SELECT r"x"#c`}},
+		{"SELECT b'''x'''/* c */, 1;", []string{"SELECT b'''x''' , 1"}, []string{"SELECT b'''x'''/* c */, 1"}},
+		{"SELECT 'x'/* c */1;", []string{"SELECT 'x' 1"}, []string{"SELECT 'x'/* c */1"}},
+		{"SELECT `id`/* ; */;SELECT 2", []string{"SELECT `id`", "SELECT 2"}, []string{"SELECT `id`/* ; */", "SELECT 2"}},
+	} {
+		if diff := cmp.Diff(tt.want, statements(SeparateInput(tt.input))); diff != "" {
+			t.Errorf("SeparateInput(%q): difference in statements: (-want +got):\n%s", tt.input, diff)
+		}
+		if diff := cmp.Diff(tt.wantPreserved, statements(SeparateInputPreserveComments(tt.input))); diff != "" {
+			t.Errorf("SeparateInputPreserveComments(%q): difference in statements: (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestSeparator_BlockKeywords(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []string
+	}{
+		{
+			desc:  "block with internal semicolons",
+			input: "BEGIN\n  SELECT 1;\n  SELECT 2;\nEND;\nSELECT 3;",
+			want:  []string{"BEGIN\n  SELECT 1;\n  SELECT 2;\nEND", "SELECT 3"},
+		},
+		{
+			desc:  "case-insensitive nested blocks",
+			input: "begin SELECT 1; Begin SELECT 2; end; SELECT 3; End; SELECT 4",
+			want:  []string{"begin SELECT 1; Begin SELECT 2; end; SELECT 3; End", "SELECT 4"},
+		},
+		{
+			desc:  "keywords in strings, comments, and identifiers",
+			input: "SELECT 'BEGIN', `BEGIN` /* BEGIN */; SELECT BEGINNING, END_DATE; SELECT 1",
+			want:  []string{"SELECT 'BEGIN', `BEGIN`", "SELECT BEGINNING, END_DATE", "SELECT 1"},
+		},
+		{
+			desc:  "unmatched end",
+			input: "SELECT 1 END; BEGIN SELECT 2; END; SELECT 3",
+			want:  []string{"SELECT 1 END", "BEGIN SELECT 2; END", "SELECT 3"},
+		},
+		{
+			desc:  "unclosed block",
+			input: "BEGIN SELECT 1; SELECT 2",
+			want:  []string{"BEGIN SELECT 1; SELECT 2"},
+		},
+		{
+			desc:  "custom terminator in block",
+			input: "BEGIN SELECT 1\\G END\\G SELECT 2",
+			want:  []string{"BEGIN SELECT 1\\G END", "SELECT 2"},
+		},
+		{
+			desc:  "case expression in block",
+			input: "BEGIN SELECT CASE WHEN x THEN 1 END; SELECT 2; END; SELECT 3",
+			want:  []string{"BEGIN SELECT CASE WHEN x THEN 1 END; SELECT 2; END", "SELECT 3"},
+		},
+		{
+			desc:  "case expression outside of blocks",
+			input: "SELECT case x WHEN 1 THEN 'a;' END; SELECT 2",
+			want:  []string{"SELECT case x WHEN 1 THEN 'a;' END", "SELECT 2"},
+		},
+		{
+			desc:  "compound statements in block",
+			input: "BEGIN IF x THEN SELECT 1; END IF; LOOP LEAVE; END LOOP; CASE WHEN y THEN SELECT 2; END CASE; SELECT 3; END; SELECT 4",
+			want:  []string{"BEGIN IF x THEN SELECT 1; END IF; LOOP LEAVE; END LOOP; CASE WHEN y THEN SELECT 2; END CASE; SELECT 3; END", "SELECT 4"},
+		},
+		{
+			desc:  "comment between end and keyword",
+			input: "BEGIN WHILE x DO SELECT 1; END /* while */ WHILE; END; SELECT 2",
+			want:  []string{"BEGIN WHILE x DO SELECT 1; END   WHILE; END", "SELECT 2"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithBlockKeywords("BEGIN", "END"), WithTerminators(`\G`)).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	got := SeparateInput("BEGIN SELECT 1; END;")
+	if diff := cmp.Diff([]string{"BEGIN SELECT 1", "END"}, statements(got)); diff != "" {
+		t.Errorf("difference in statements without WithBlockKeywords: (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeparator_BlankLineSeparator(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "two statements separated by a blank line",
+			input: "SELECT 1\nFROM t\n\nSELECT 2",
+			want:  []InputStatement{{Statement: "SELECT 1\nFROM t", Terminated: true}, {Statement: "SELECT 2"}},
+		},
+		{
+			desc:  "consecutive blank lines with whitespaces",
+			input: "\n\nSELECT 1 \r\n \t\r\n\n  \nSELECT 2;\n\nSELECT 3;\n\n",
+			want:  []InputStatement{{Statement: "SELECT 1", Terminated: true}, {Statement: "SELECT 2", Terminator: ";", Terminated: true}, {Statement: "SELECT 3", Terminator: ";", Terminated: true}},
+		},
+		{
+			desc:  "blank lines in strings and comments",
+			input: "SELECT '''a\n\nb''', /* c\n\nd */ 1\n\nSELECT \"\"\"\n\n\"\"\"",
+			want:  []InputStatement{{Statement: "SELECT '''a\n\nb''',   1", Terminated: true}, {Statement: "SELECT \"\"\"\n\n\"\"\""}},
+		},
+		{
+			desc:  "blank line after a line comment",
+			input: "SELECT 1 -- comment\n\nSELECT 2",
+			want:  []InputStatement{{Statement: "SELECT 1", Terminated: true}, {Statement: "SELECT 2"}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, WithBlankLineSeparator(true)).separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositionsKeepTerminated); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if got := SeparateInput("SELECT 1\n\nSELECT 2"); len(got) != 1 {
+		t.Errorf("SeparateInput() without WithBlankLineSeparator = %#v, but want a statement", got)
+	}
+}
+
+func TestSeparator_WordBoundaryTerminators(t *testing.T) {
+	const input = "SELECT 'google' AS google, ago FROM goods go\nSELECT 1 go_ SELECT 2\tgo SELECT 3\\G SELECT 4 go"
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		want []string
+	}{
+		{
+			desc: "word boundaries",
+			opts: []Option{WithWordBoundaryTerminators(true)},
+			want: []string{"SELECT 'google' AS google, ago FROM goods", "SELECT 1 go_ SELECT 2", "SELECT 3", "SELECT 4"},
+		},
+		{
+			desc: "default",
+			want: []string{"SELECT 'google' AS", "ogle, a", "FROM", "ods", "SELECT 1", "_ SELECT 2", "SELECT 3", "SELECT 4"},
+		},
+		{
+			desc: "kept terminators",
+			opts: []Option{WithWordBoundaryTerminators(true), WithKeepTerminator(true)},
+			want: []string{"SELECT 'google' AS google, ago FROM goods go", "SELECT 1 go_ SELECT 2\tgo", "SELECT 3\\G", "SELECT 4 go"},
+		},
+		{
+			desc: "kept case-insensitive terminators",
+			opts: []Option{WithCaseInsensitiveTerminators(true), WithKeepTerminator(true)},
+			want: []string{"SELECT 'google' AS google, ago FROM goods go", "SELECT 1 go_ SELECT 2\tgo", "SELECT 3\\G", "SELECT 4 go"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(input, append(tt.opts, WithTerminators("go", `\G`))...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// statements with kept terminators should be separated into the same statements again.
+	for _, opts := range [][]Option{
+		{WithWordBoundaryTerminators(true)},
+		{WithCaseInsensitiveTerminators(true)},
+	} {
+		opts := append(opts, WithTerminators("go"), WithKeepTerminator(true))
+		first, _ := NewSeparator("SELECT 1 go SELECT 2 -- comment\ngo", opts...).separate()
+		second, _ := NewSeparator(strings.Join(statements(first), "\n"), opts...).separate()
+		if diff := cmp.Diff(statements(first), statements(second)); diff != "" {
+			t.Errorf("difference in statements separated again: (-first +second):\n%s", diff)
+		}
+		if len(first) != 2 {
+			t.Errorf("separate() = %q, but want 2 statements", statements(first))
+		}
+	}
+}
+
+func TestSeparator_CollapseWhitespace(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []string
+	}{
+		{
+			desc:  "whitespaces outside of strings",
+			input: "SELECT\n\t1,  \r\n 2;\nSELECT\t\t*\nFROM t",
+			want:  []string{"SELECT 1, 2", "SELECT * FROM t"},
+		},
+		{
+			desc:  "whitespaces in strings and identifiers",
+			input: "SELECT  'a  b',\n\"\"\"c\n\td\"\"\",  `e  f`,   r'g\t h'",
+			want:  []string{"SELECT 'a  b', \"\"\"c\n\td\"\"\", `e  f`, r'g\t h'"},
+		},
+		{
+			desc:  "stripped comments",
+			input: "SELECT /* a */ 1 -- b\n  ,/*c*/2",
+			want:  []string{"SELECT 1 , 2"},
+		},
+		{
+			desc:  "preserved comments",
+			input: "SELECT  /*  a  */  1 --  b\n  , 2",
+			opts:  []Option{WithPreserveComments(true)},
+			want:  []string{"SELECT /*  a  */ 1 --  b\n , 2"},
+		},
+		{
+			desc:  "without trimming",
+			input: "\n  SELECT\n\n1\n;",
+			opts:  []Option{WithTrimSpace(false)},
+			want:  []string{" SELECT 1 "},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, append(tt.opts, WithCollapseWhitespace(true))...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_ExtractHeader(t *testing.T) {
+	const header = "/*\n * Copyright 2020 Google LLC\n *\n * Licensed under the Apache License, Version 2.0\n */\n-- generated file\n\n"
+	for _, tt := range []struct {
+		desc       string
+		input      string
+		opts       []Option
+		want       []InputStatement
+		wantHeader string
+	}{
+		{
+			desc:  "license header",
+			input: header + "SELECT 1;\n/* not a header */ SELECT 2;",
+			want: []InputStatement{
+				{Statement: "SELECT 1", Terminator: ";", Header: "/*\n * Copyright 2020 Google LLC\n *\n * Licensed under the Apache License, Version 2.0\n */\n-- generated file"},
+				{Statement: "SELECT 2", Terminator: ";"},
+			},
+			wantHeader: "/*\n * Copyright 2020 Google LLC\n *\n * Licensed under the Apache License, Version 2.0\n */\n-- generated file",
+		},
+		{
+			desc:  "preserved comments",
+			input: "-- header\nSELECT 1 /* c */;",
+			opts:  []Option{WithPreserveComments(true), WithLeadingComments(true)},
+			want: []InputStatement{
+				{Statement: "SELECT 1 /* c */", Terminator: ";", Header: "-- header"},
+			},
+			wantHeader: "-- header",
+		},
+		{
+			desc:       "only header",
+			input:      "/* header */\n",
+			want:       nil,
+			wantHeader: "/* header */",
+		},
+		{
+			desc:  "no header",
+			input: "SELECT 1",
+			want:  []InputStatement{{Statement: "SELECT 1"}},
+		},
+		{
+			desc:  "unclosed comment",
+			input: "/* header\nSELECT 1;",
+			opts:  []Option{WithPreserveComments(true)},
+			want:  []InputStatement{{Statement: "/* header\nSELECT 1;"}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := NewSeparator(tt.input, append(tt.opts, WithExtractHeader(true))...)
+			got, _ := s.separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if got := s.Header(); got != tt.wantHeader {
+				t.Errorf("Header() = %q, but want = %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestSeparator_NormalizedTerminators(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		opts  []Option
+		input string
+		want  []InputStatement
+	}{
+		{
+			desc:  "duplicated terminators",
+			opts:  []Option{WithTerminators(`\G`, `\G`), WithVerticalTerminator()},
+			input: `SELECT 1\GSELECT 2;`,
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: `\G`}, {Statement: "SELECT 2", Terminator: ";"}},
+		},
+		{
+			desc:  "empty terminator",
+			opts:  []Option{WithTerminators("", `\G`)},
+			input: `SELECT 1\GSELECT 2;`,
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: `\G`}, {Statement: "SELECT 2", Terminator: ";"}},
+		},
+		{
+			desc:  "terminator equal to the primary terminator",
+			opts:  []Option{WithTerminators(";", `\G`)},
+			input: `SELECT 1;SELECT 2\G`,
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: ";"}, {Statement: "SELECT 2", Terminator: `\G`}},
+		},
+		{
+			desc:  "semicolon with another primary terminator",
+			opts:  []Option{WithPrimaryTerminator("/"), WithTerminators("/", ";")},
+			input: `SELECT 1/SELECT 2;`,
+			want:  []InputStatement{{Statement: "SELECT 1", Terminator: "/"}, {Statement: "SELECT 2", Terminator: ";"}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := NewSeparator(tt.input, tt.opts...)
+			got, _ := s.separate()
+			if diff := cmp.Diff(tt.want, got, ignorePositions); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			if len(s.terms) != 1 {
+				t.Errorf("terminators = %q, but want a terminator", s.terms)
+			}
+		})
+	}
+}
+
+func TestSeparator_EmptyTerminatorDoesNotHang(t *testing.T) {
+	const input = "SELECT 1; SELECT 2\\G SELECT 3"
+	want := []string{"SELECT 1", "SELECT 2", "SELECT 3"}
+	timeout := 5 * time.Second
+	if deadline, ok := t.Deadline(); ok && time.Until(deadline)/2 < timeout {
+		timeout = time.Until(deadline) / 2
+	}
+	for _, tt := range []struct {
+		desc string
+		fn   func() []string
+	}{
+		{"SeparateInput", func() []string { return statements(SeparateInput(input, "", `\G`)) }},
+		{"SeparateInputString", func() []string { return SeparateInputString(input, "", `\G`) }},
+		{"case-insensitive", func() []string {
+			got, _ := NewSeparator(input, WithTerminators("", `\g`), WithCaseInsensitiveTerminators(true)).separate()
+			return statements(got)
+		}},
+		{"SeparateReader", func() []string {
+			got, _ := SeparateReader(strings.NewReader(input), "", `\G`)
+			return statements(got)
+		}},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			done := make(chan []string, 1)
+			go func() { done <- tt.fn() }()
+			select {
+			case got := <-done:
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("difference in statements: (-want +got):\n%s", diff)
+				}
+			case <-time.After(timeout):
+				t.Fatalf("separation with an empty terminator doesn't finish in %v", timeout)
+			}
+		})
+	}
+}
+
+func TestSeparator_RunOfDashes(t *testing.T) {
+	for _, tt := range []struct {
+		desc          string
+		input         string
+		opts          []Option
+		want          []string
+		wantTokenKind []TokenKind
+	}{
+		{
+			desc:          "three dashes",
+			input:         "SELECT a---b\nFROM t",
+			want:          []string{"SELECT a FROM t"},
+			wantTokenKind: []TokenKind{TokenOther, TokenComment, TokenOther},
+		},
+		{
+			desc:          "four dashes",
+			input:         "SELECT a----b;",
+			want:          []string{"SELECT a"},
+			wantTokenKind: []TokenKind{TokenOther, TokenComment},
+		},
+		{
+			desc:          "separated dashes",
+			input:         "SELECT a - -b;",
+			want:          []string{"SELECT a - -b"},
+			wantTokenKind: []TokenKind{TokenOther, TokenTerminator},
+		},
+		{
+			desc:          "three dashes requiring space",
+			input:         "SELECT a---b;",
+			opts:          []Option{WithDashCommentRequireSpace(true)},
+			want:          []string{"SELECT a---b"},
+			wantTokenKind: []TokenKind{TokenOther, TokenTerminator},
+		},
+		{
+			desc:          "three dashes followed by space requiring space",
+			input:         "SELECT a --- b\n;",
+			opts:          []Option{WithDashCommentRequireSpace(true)},
+			want:          []string{"SELECT a -"},
+			wantTokenKind: []TokenKind{TokenOther, TokenComment, TokenTerminator},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+			var kinds []TokenKind
+			for _, token := range Tokenize(tt.input, tt.opts...) {
+				kinds = append(kinds, token.Kind)
+			}
+			if diff := cmp.Diff(tt.wantTokenKind, kinds); diff != "" {
+				t.Errorf("difference in token kinds: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparator_PreserveTerminatorWhitespace(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []InputStatement
+	}{
+		{
+			desc:  "keep terminator",
+			input: "  SELECT 1   ;\nSELECT 2\n\t\\G\nSELECT 3;SELECT 4  ",
+			opts:  []Option{WithKeepTerminator(true), WithVerticalTerminator()},
+			want: []InputStatement{
+				{Statement: "SELECT 1   ;", Terminator: ";", Start: 2, End: 10},
+				{Statement: "SELECT 2\n\t\\G", Terminator: `\G`, Start: 15, End: 23},
+				{Statement: "SELECT 3;", Terminator: ";", Start: 28, End: 36},
+				{Statement: "SELECT 4", Terminator: "", Start: 37, End: 45},
+			},
+		},
+		{
+			desc:  "without keeping terminator",
+			input: "SELECT 1 \t;",
+			want:  []InputStatement{{Statement: "SELECT 1 \t", Terminator: ";", Start: 0, End: 8}},
+		},
+		{
+			desc:  "stripped comment before terminator",
+			input: "SELECT 1 /* c */ ;",
+			opts:  []Option{WithKeepTerminator(true)},
+			want:  []InputStatement{{Statement: "SELECT 1   ;", Terminator: ";", Start: 0, End: 8}},
+		},
+		{
+			desc:  "preserved comment before terminator",
+			input: "SELECT 1 /* c */ ;",
+			opts:  []Option{WithKeepTerminator(true), WithPreserveComments(true)},
+			want:  []InputStatement{{Statement: "SELECT 1 /* c */ ;", Terminator: ";", Start: 0, End: 16}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, append(tt.opts, WithPreserveTerminatorWhitespace(true))...).separate()
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(InputStatement{}, "Line", "Column", "TerminatorOffset", "Terminated", "Index")); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeparatePartial(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		chunks     []string
+		want       [][]string
+		wantTails  []string
+		wantStatus []Status
+	}{
+		{
+			desc:       "statement across chunks",
+			chunks:     []string{"SELECT 1; SELECT", " 2;\nSELECT 3;\n"},
+			want:       [][]string{{"SELECT 1"}, {"SELECT 2", "SELECT 3"}},
+			wantTails:  []string{" SELECT", ""},
+			wantStatus: []Status{{}, {}},
+		},
+		{
+			desc:      "string and comment across chunks",
+			chunks:    []string{"SELECT 'a;  ", "b'; /* c; ", "*/ SELECT 2\\G"},
+			want:      [][]string{nil, {"SELECT 'a;  b'"}, {"SELECT 2"}},
+			wantTails: []string{"SELECT 'a;  ", " /* c; ", ""},
+			wantStatus: []Status{
+				{WaitingString: "'", WaitingKind: WaitingStringLiteral},
+				{WaitingString: "*/", WaitingKind: WaitingComment},
+				{},
+			},
+		},
+		{
+			desc:       "unterminated statement at the end",
+			chunks:     []string{"SELECT 1;", "SELECT 2"},
+			want:       [][]string{{"SELECT 1"}, nil},
+			wantTails:  []string{"", "SELECT 2"},
+			wantStatus: []Status{{}, {}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var tail string
+			for i, chunk := range tt.chunks {
+				complete, newTail, status := SeparatePartial(tail+chunk, `\G`)
+				if diff := cmp.Diff(tt.want[i], statements(complete)); diff != "" {
+					t.Errorf("chunk %d: difference in statements: (-want +got):\n%s", i, diff)
+				}
+				if newTail != tt.wantTails[i] {
+					t.Errorf("chunk %d: tail = %q, but want = %q", i, newTail, tt.wantTails[i])
+				}
+				if diff := cmp.Diff(tt.wantStatus[i], status); diff != "" {
+					t.Errorf("chunk %d: difference in status: (-want +got):\n%s", i, diff)
+				}
+				tail = newTail
+			}
+		})
+	}
+}
+
+func TestSeparator_PipeSyntax(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []string
+	}{
+		{
+			desc:  "pipe operators",
+			input: "FROM t\n|> WHERE a || b = 'c;'\n|> SELECT a;FROM u|>LIMIT 1;",
+			want:  []string{"FROM t\n|> WHERE a || b = 'c;'\n|> SELECT a", "FROM u|>LIMIT 1"},
+		},
+		{
+			desc:  "pipe operators with custom terminators",
+			input: "FROM t |> WHERE a||b |> SELECT a\\G SELECT 'a'||'b'//",
+			opts:  []Option{WithVerticalTerminator(), WithTerminators("//")},
+			want:  []string{"FROM t |> WHERE a||b |> SELECT a", "SELECT 'a'||'b'"},
+		},
+		{
+			desc:  "pipe operators and comments",
+			input: "FROM t -- |> ;\n|> /* ; */ SELECT a|>-- x\nLIMIT 1;",
+			want:  []string{"FROM t  |>   SELECT a|> LIMIT 1"},
+		},
+		{
+			desc:  "bar terminator collides with operators",
+			input: "FROM t |> WHERE a || b",
+			opts:  []Option{WithTerminators("|")},
+			want:  []string{"FROM t", "> WHERE a", "", "b"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, _ := NewSeparator(tt.input, tt.opts...).separate()
+			if diff := cmp.Diff(tt.want, statements(got)); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build go1.23
+
+package gsqlsep
+
+import "iter"
+
+// SeparateSeq returns an iterator over statements in input, which yields same statements as SeparateInput.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateSeq(input string, customTerminators ...string) iter.Seq[InputStatement] {
+	return func(yield func(InputStatement) bool) {
+		s := newSeparator(input, false, customTerminators)
+		for {
+			stmt, ok := s.Next()
+			if !ok || !yield(stmt) {
+				return
+			}
+		}
+	}
+}
+
+// SeparateSeqString returns an iterator over statements in input, which yields same statements as SeparateInputString.
+// This function strip all comments in input.
+// By default, input will be separated by terminating semicolons `;`.
+// In addition, customTerminators can be passed, and they will be treated as terminating semicolons.
+func SeparateSeqString(input string, customTerminators ...string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for stmt := range SeparateSeq(input, customTerminators...) {
+			if !yield(stmt.Statement) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build go1.23
+
+package gsqlsep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeparateSeq(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"SELECT 1; SELECT 2\\G SELECT 3",
+		"/* comment */ SELECT 1; -- comment\n;SELECT '2",
+	} {
+		var got []InputStatement
+		for stmt := range SeparateSeq(input, `\G`) {
+			got = append(got, stmt)
+		}
+		if diff := cmp.Diff(SeparateInput(input, `\G`), got); diff != "" {
+			t.Errorf("SeparateSeq(%q) differs from SeparateInput: (-want +got):\n%s", input, diff)
+		}
+
+		var gotString []string
+		for stmt := range SeparateSeqString(input, `\G`) {
+			gotString = append(gotString, stmt)
+		}
+		if diff := cmp.Diff(SeparateInputString(input, `\G`), gotString); diff != "" {
+			t.Errorf("SeparateSeqString(%q) differs from SeparateInputString: (-want +got):\n%s", input, diff)
+		}
+	}
+}
+
+func TestSeparateSeq_Break(t *testing.T) {
+	var got []string
+	for stmt := range SeparateSeqString("SELECT 1; SELECT 2; SELECT 3;") {
+		got = append(got, stmt)
+		if stmt == "SELECT 2" {
+			break
+		}
+	}
+	if diff := cmp.Diff([]string{"SELECT 1", "SELECT 2"}, got); diff != "" {
+		t.Errorf("difference in statements: (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,185 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind is a kind of a token.
+type TokenKind int
+
+const (
+	// TokenOther is a run of ordinary characters including whitespaces, keywords, and operators.
+	TokenOther TokenKind = iota
+	// TokenString is a string literal, including an escape string enabled by WithEscapeStringPrefix.
+	TokenString
+	// TokenRawString is a raw string literal, including a dollar-quoted string enabled by WithDollarQuoting.
+	TokenRawString
+	// TokenBytesString is a bytes literal, including a raw bytes literal.
+	TokenBytesString
+	// TokenIdentifier is a quoted identifier.
+	TokenIdentifier
+	// TokenComment is a single line comment or a multiline comment.
+	TokenComment
+	// TokenTerminator is a terminator of a statement.
+	TokenTerminator
+	// TokenTypedLiteral is a keyword-prefixed string literal like `DATE '2020-01-01'` enabled by WithTypedLiterals.
+	TokenTypedLiteral
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenOther:
+		return "Other"
+	case TokenString:
+		return "String"
+	case TokenRawString:
+		return "RawString"
+	case TokenBytesString:
+		return "BytesString"
+	case TokenIdentifier:
+		return "Identifier"
+	case TokenComment:
+		return "Comment"
+	case TokenTerminator:
+		return "Terminator"
+	case TokenTypedLiteral:
+		return "TypedLiteral"
+	default:
+		return fmt.Sprintf("TokenKind(%d)", int(k))
+	}
+}
+
+// Token is a token in input.
+type Token struct {
+	Kind TokenKind
+	// Text is the verbatim text of the token in input.
+	// A single line comment includes its line terminator.
+	Text string
+	// Start and End are byte offsets of the token in input.
+	Start, End int
+}
+
+// Tokenize splits input into tokens with opts, which are recognized as same as the Separator.
+// Statements are tokens grouped by TokenTerminator.
+// Consecutive ordinary characters are merged into a single TokenOther.
+// An unclosed token at the end of input is returned as is.
+func Tokenize(input string, opts ...Option) []Token {
+	var tokens []Token
+	s := NewSeparator(input, opts...)
+	s.discard = true
+	s.tokenFn = func(token Token) {
+		if last := len(tokens) - 1; token.Kind == TokenOther && last >= 0 &&
+			tokens[last].Kind == TokenOther && tokens[last].End == token.Start {
+			tokens[last].Text = input[tokens[last].Start:token.End]
+			tokens[last].End = token.End
+			return
+		}
+		tokens = append(tokens, token)
+	}
+	for {
+		if _, ok := s.Next(); !ok {
+			return tokens
+		}
+	}
+}
+
+// Region is a kind of a region in input, which is used to classify an offset.
+type Region int
+
+const (
+	// RegionCode is outside of strings, comments, and quoted identifiers, including terminators.
+	RegionCode Region = iota
+	// RegionString is in a string literal or a bytes literal, including its prefix and quotes.
+	RegionString
+	// RegionComment is in a comment.
+	RegionComment
+	// RegionIdentifier is in a quoted identifier, including its backticks.
+	RegionIdentifier
+)
+
+func (r Region) String() string {
+	switch r {
+	case RegionCode:
+		return "Code"
+	case RegionString:
+		return "String"
+	case RegionComment:
+		return "Comment"
+	case RegionIdentifier:
+		return "Identifier"
+	default:
+		return fmt.Sprintf("Region(%d)", int(r))
+	}
+}
+
+// ClassifyOffset returns the region of the byte at offset in input, which is recognized with opts as same as Tokenize.
+// Input is scanned only up to the token containing offset.
+// offset at the end of input is in the unclosed token or the single line comment without a line terminator
+// at the end of input if any, otherwise it is RegionCode.
+// The keyword of a typed literal enabled by WithTypedLiterals is RegionCode.
+func ClassifyOffset(input string, offset int, opts ...Option) Region {
+	if offset < 0 || offset > len(input) {
+		return RegionCode
+	}
+	region, found := RegionCode, false
+	var last Token
+	s := NewSeparator(input, opts...)
+	s.discard = true
+	s.tokenFn = func(token Token) {
+		last = token
+		if found || offset < token.Start || offset >= token.End {
+			return
+		}
+		found = true
+		switch token.Kind {
+		case TokenString, TokenRawString, TokenBytesString:
+			region = RegionString
+		case TokenTypedLiteral:
+			if i := strings.IndexAny(token.Text, `'"`); offset >= token.Start+i {
+				region = RegionString
+			}
+		case TokenIdentifier:
+			region = RegionIdentifier
+		case TokenComment:
+			region = RegionComment
+		}
+	}
+	for !found {
+		if _, ok := s.Next(); !ok {
+			break
+		}
+	}
+	if !found && offset == len(input) {
+		switch s.Status().WaitingKind {
+		case WaitingStringLiteral:
+			return RegionString
+		case WaitingQuotedIdentifier:
+			return RegionIdentifier
+		case WaitingComment:
+			return RegionComment
+		}
+		if last.Kind == TokenComment && last.End == len(input) &&
+			!strings.HasPrefix(last.Text, s.blockCommentOpen) && !strings.HasSuffix(last.Text, "\n") {
+			// a single line comment without a line terminator
+			return RegionComment
+		}
+	}
+	return region
+}
@@ -0,0 +1,213 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gsqlsep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []Option
+		want  []Token
+	}{
+		{
+			desc:  "empty input",
+			input: "",
+			want:  nil,
+		},
+		{
+			desc:  "mixed input",
+			input: "SELECT 'a', r\"b\", b'c', rb'd', `e` -- comment\n/* comment */;\nSELECT 1\\G",
+			opts:  []Option{WithTerminators(`\G`)},
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT ", Start: 0, End: 7},
+				{Kind: TokenString, Text: "'a'", Start: 7, End: 10},
+				{Kind: TokenOther, Text: ", ", Start: 10, End: 12},
+				{Kind: TokenRawString, Text: `r"b"`, Start: 12, End: 16},
+				{Kind: TokenOther, Text: ", ", Start: 16, End: 18},
+				{Kind: TokenBytesString, Text: "b'c'", Start: 18, End: 22},
+				{Kind: TokenOther, Text: ", ", Start: 22, End: 24},
+				{Kind: TokenBytesString, Text: "rb'd'", Start: 24, End: 29},
+				{Kind: TokenOther, Text: ", ", Start: 29, End: 31},
+				{Kind: TokenIdentifier, Text: "`e`", Start: 31, End: 34},
+				{Kind: TokenOther, Text: " ", Start: 34, End: 35},
+				{Kind: TokenComment, Text: "-- comment\n", Start: 35, End: 46},
+				{Kind: TokenComment, Text: "/* comment */", Start: 46, End: 59},
+				{Kind: TokenTerminator, Text: ";", Start: 59, End: 60},
+				{Kind: TokenOther, Text: "\nSELECT 1", Start: 60, End: 69},
+				{Kind: TokenTerminator, Text: `\G`, Start: 69, End: 71},
+			},
+		},
+		{
+			desc:  "string-like prefixes in identifiers",
+			input: "SELECT rb, br FROM b",
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT rb, br FROM b", Start: 0, End: 20},
+			},
+		},
+		{
+			desc:  "dollar-quoted string",
+			input: "SELECT $x$;$x$;",
+			opts:  []Option{WithDollarQuoting(true)},
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT ", Start: 0, End: 7},
+				{Kind: TokenRawString, Text: "$x$;$x$", Start: 7, End: 14},
+				{Kind: TokenTerminator, Text: ";", Start: 14, End: 15},
+			},
+		},
+		{
+			desc:  "unclosed string",
+			input: "SELECT 1; SELECT '2;",
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT 1", Start: 0, End: 8},
+				{Kind: TokenTerminator, Text: ";", Start: 8, End: 9},
+				{Kind: TokenOther, Text: " SELECT ", Start: 9, End: 17},
+				{Kind: TokenString, Text: "'2;", Start: 17, End: 20},
+			},
+		},
+		{
+			desc:  "typed literals",
+			input: "SELECT DATE '2020-01-01', timestamp\n\"2020-01-01 00:00:00;\", DATETIME, mydate'x'",
+			opts:  []Option{WithTypedLiterals(true)},
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT ", Start: 0, End: 7},
+				{Kind: TokenTypedLiteral, Text: "DATE '2020-01-01'", Start: 7, End: 24},
+				{Kind: TokenOther, Text: ", ", Start: 24, End: 26},
+				{Kind: TokenTypedLiteral, Text: "timestamp\n\"2020-01-01 00:00:00;\"", Start: 26, End: 58},
+				{Kind: TokenOther, Text: ", DATETIME, mydate", Start: 58, End: 76},
+				{Kind: TokenString, Text: "'x'", Start: 76, End: 79},
+			},
+		},
+		{
+			desc:  "quoted parameter names",
+			input: "SELECT @`p;`, @q, @`r",
+			opts:  []Option{WithCollectPlaceholders(true)},
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT @", Start: 0, End: 8},
+				{Kind: TokenIdentifier, Text: "`p;`", Start: 8, End: 12},
+				{Kind: TokenOther, Text: ", @q, @", Start: 12, End: 19},
+				{Kind: TokenIdentifier, Text: "`r", Start: 19, End: 21},
+			},
+		},
+		{
+			desc:  "typed literals disabled",
+			input: "SELECT DATE '2020-01-01'",
+			want: []Token{
+				{Kind: TokenOther, Text: "SELECT DATE ", Start: 0, End: 12},
+				{Kind: TokenString, Text: "'2020-01-01'", Start: 12, End: 24},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := Tokenize(tt.input, tt.opts...)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in tokens: (-want +got):\n%s", diff)
+			}
+
+			var sb strings.Builder
+			for _, token := range got {
+				sb.WriteString(token.Text)
+			}
+			if joined := sb.String(); joined != tt.input {
+				t.Errorf("joined tokens = %q, but want = %q", joined, tt.input)
+			}
+		})
+	}
+}
+
+func TestTokenKind_String(t *testing.T) {
+	for _, tt := range []struct {
+		kind TokenKind
+		want string
+	}{
+		{TokenOther, "Other"},
+		{TokenString, "String"},
+		{TokenRawString, "RawString"},
+		{TokenBytesString, "BytesString"},
+		{TokenIdentifier, "Identifier"},
+		{TokenComment, "Comment"},
+		{TokenTerminator, "Terminator"},
+		{TokenTypedLiteral, "TypedLiteral"},
+		{TokenKind(100), "TokenKind(100)"},
+	} {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("TokenKind(%d).String() = %q, but want = %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
+
+func TestClassifyOffset(t *testing.T) {
+	const input = "SELECT 'a;b', `c` /* d */ -- e\n, DATE '2020-01-01';\nSELECT \"f"
+	for _, tt := range []struct {
+		desc   string
+		input  string
+		offset int
+		opts   []Option
+		want   Region
+	}{
+		{desc: "keyword", input: input, offset: 2, want: RegionCode},
+		{desc: "opening quote", input: input, offset: 7, want: RegionString},
+		{desc: "in string", input: input, offset: 9, want: RegionString},
+		{desc: "closing quote", input: input, offset: 11, want: RegionString},
+		{desc: "after string", input: input, offset: 12, want: RegionCode},
+		{desc: "in identifier", input: input, offset: 15, want: RegionIdentifier},
+		{desc: "in multiline comment", input: input, offset: 21, want: RegionComment},
+		{desc: "between comments", input: input, offset: 25, want: RegionCode},
+		{desc: "line terminator of single line comment", input: input, offset: 30, want: RegionComment},
+		{desc: "keyword of typed literal", input: input, offset: 34, opts: []Option{WithTypedLiterals(true)}, want: RegionCode},
+		{desc: "string of typed literal", input: input, offset: 40, opts: []Option{WithTypedLiterals(true)}, want: RegionString},
+		{desc: "terminator", input: input, offset: 50, want: RegionCode},
+		{desc: "in unclosed string", input: input, offset: 60, want: RegionString},
+		{desc: "end of unclosed string", input: input, offset: len(input), want: RegionString},
+		{desc: "end of closed string", input: "SELECT 'a'", offset: 10, want: RegionCode},
+		{desc: "end of unclosed comment", input: "SELECT 1 /* a", offset: 13, want: RegionComment},
+		{desc: "end of closed comment", input: "SELECT 1 /* a */", offset: 16, want: RegionCode},
+		{desc: "end of single line comment", input: "SELECT 1 -- a", offset: 13, want: RegionComment},
+		{desc: "end of unclosed identifier", input: "SELECT `a", offset: 9, want: RegionIdentifier},
+		{desc: "out of range", input: "'a'", offset: 4, want: RegionCode},
+		{desc: "negative", input: "'a'", offset: -1, want: RegionCode},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := ClassifyOffset(tt.input, tt.offset, tt.opts...); got != tt.want {
+				t.Errorf("ClassifyOffset(%q, %d) = %v, but want = %v", tt.input, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegion_String(t *testing.T) {
+	for _, tt := range []struct {
+		region Region
+		want   string
+	}{
+		{RegionCode, "Code"},
+		{RegionString, "String"},
+		{RegionComment, "Comment"},
+		{RegionIdentifier, "Identifier"},
+		{Region(100), "Region(100)"},
+	} {
+		if got := tt.region.String(); got != tt.want {
+			t.Errorf("Region(%d).String() = %q, but want = %q", int(tt.region), got, tt.want)
+		}
+	}
+}